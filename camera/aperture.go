@@ -0,0 +1,130 @@
+package camera
+
+import (
+	"errors"
+	"gotracer/texture"
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Aperture shape of a lens, defines the shape of the out of focus highlights (bokeh).
+type ApertureShape interface {
+	// Sample a random point of the aperture, the X and Y coordinates are in the [-1, 1] range and are scaled by the lens radius.
+	Sample() *vmath.Vector3
+}
+
+// Circular aperture, out of focus highlights are round.
+type CircularAperture struct {}
+
+// Create new circular aperture.
+func NewCircularAperture() *CircularAperture {
+	return new(CircularAperture)
+}
+
+func (a *CircularAperture) Sample() *vmath.Vector3 {
+	return vmath.RandomInUnitDisk()
+}
+
+// Regular polygon aperture formed by the diaphragm blades, inscribed in the unit circle.
+type PolygonAperture struct {
+	// Number of blades of the diaphragm, at least 3.
+	Blades int
+
+	// Rotation of the polygon in degrees.
+	Rotation float64
+}
+
+// Create new polygon aperture, returns a error if there are less than 3 blades.
+func NewPolygonAperture(blades int, rotation float64) (*PolygonAperture, error) {
+	if blades < 3 {
+		return nil, errors.New("aperture needs at least 3 blades")
+	}
+
+	var a = new(PolygonAperture)
+	a.Blades = blades
+	a.Rotation = rotation
+	return a, nil
+}
+
+// All triangles between the center and the edges have the same area, a random triangle is selected and a point is sampled uniformly over it.
+func (a *PolygonAperture) Sample() *vmath.Vector3 {
+	var step = 2.0 * math.Pi / float64(a.Blades)
+	var edge = float64(rand.Intn(a.Blades))
+	var start = a.Rotation * (math.Pi / 180.0) + edge * step
+
+	var s = rand.Float64()
+	var t = rand.Float64()
+	if s + t > 1.0 {
+		s = 1.0 - s
+		t = 1.0 - t
+	}
+
+	var x = s * math.Cos(start) + t * math.Cos(start + step)
+	var y = s * math.Sin(start) + t * math.Sin(start + step)
+
+	return vmath.NewVector3(x, y, 0.0)
+}
+
+// Aperture with a custom shape from a grayscale image, brighter pixels let more light pass.
+// The image is fitted into the [-1, 1] square centered in the lens, the corners are outside of the unit disk and should be black.
+type ImageAperture struct {
+	Width int
+	Height int
+
+	// Cumulative luminance of the pixels of the image row by row from the top, used to sample them.
+	cdf []float64
+}
+
+// Create new image aperture from the luminance of a image.
+func NewImageAperture(image *texture.ImageTexture) (*ImageAperture, error) {
+	var a = new(ImageAperture)
+	a.Width = image.Width
+	a.Height = image.Height
+	a.cdf = make([]float64, image.Width * image.Height)
+
+	var total = 0.0
+	for y := 0; y < image.Height; y++ {
+		for x := 0; x < image.Width; x++ {
+			total += vmath.Luminance(image.Pixel(x, y))
+			a.cdf[y * image.Width + x] = total
+		}
+	}
+
+	if total <= 0 {
+		return nil, errors.New("aperture image is black")
+	}
+
+	return a, nil
+}
+
+// Load a image aperture from a PNG or JPEG file.
+func LoadImageAperture(fname string) (*ImageAperture, error) {
+	var image, err = texture.LoadImageTexture(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewImageAperture(image)
+}
+
+// Pixels are selected proportionally to their luminance, and points are sampled uniformly over the pixel.
+func (a *ImageAperture) Sample() *vmath.Vector3 {
+	var total = a.cdf[len(a.cdf) - 1]
+	var i = sort.SearchFloat64s(a.cdf, rand.Float64() * total)
+	if i >= len(a.cdf) {
+		i = len(a.cdf) - 1
+	}
+
+	// Largest side of the image covers the [-1, 1] range
+	var size = float64(a.Width)
+	if a.Height > a.Width {
+		size = float64(a.Height)
+	}
+
+	var x = float64(i % a.Width) + rand.Float64() - float64(a.Width) / 2.0
+	var y = float64(a.Height) / 2.0 - float64(i / a.Width) - rand.Float64()
+
+	return vmath.NewVector3(2.0 * x / size, 2.0 * y / size, 0.0)
+}
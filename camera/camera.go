@@ -3,18 +3,31 @@ package camera
 import (
 	"github.com/faiface/pixel"
 	"gotracer/vmath"
-	"math"
+	"math/rand"
 )
 
-// CameraDefocus object describes how the objects are projected into the screen.
+// Camera describes how the objects are projected into the screen.
 // The camera object is used to get the rays that need to be casted for each screen UV coordinate.
-type Camera struct {
+type Camera interface {
+	// Get a ray from this camera, from a normalized UV screen coordinate.
+	// Returns nil if no ray is casted for the coordinate, when it is outside of the area covered by the camera projection or the ray is blocked by the lens.
+	GetRay(u float64, v float64) *vmath.Ray
+
+	// Update the camera projection properties, has to be called after the camera is changed.
+	UpdateViewport()
+
+	// Clone the camera object, the clone viewport is updated.
+	Clone() Camera
+
+	// Get the view shared by all cameras, with the position and orientation of the camera.
+	GetView() *View
+}
+
+// View contains the position, orientation and shutter of a camera, shared by all camera projections.
+type View struct {
 	// Aspect ratio of the camera viewport (X / Y)
 	AspectRatio float64
 
-	// Field of view of the camera in degrees.
-	Fov float64
-
 	// World position of the camera
 	Position *vmath.Vector3
 
@@ -24,112 +37,72 @@ type Camera struct {
 	// Up direction to calculate the camera look direction
 	Up *vmath.Vector3
 
-	// The Lower left corner of the camera relative to the center considering the vertical and horizontal sizes.
-	// Calculated by the UpdateViewport method.
-	LowerLeftCorner *vmath.Vector3
-
-	// Vertical size of the camera (usually only uses Y).
-	// Calculated by the UpdateViewport method.
-	Vertical *vmath.Vector3
-
-	// Horizontal size of the camera (usually only uses X).
-	// Calculated by the UpdateViewport method.
-	Horizontal *vmath.Vector3
+	// Time when the shutter opens and closes, rays are casted at random times between them.
+	// Moving objects are blurred along the motion done while the shutter is open.
+	ShutterOpen float64
+	ShutterClose float64
 }
 
-// Create camera from bouding box
-func NewCamera(bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3, fov float64) *Camera {
-	var c = new(Camera)
+// Create view from the bounding box of the image.
+func NewView(bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3) *View {
+	var v = new(View)
 	var size = bounds.Size()
 
-	c.Fov = fov
-	c.AspectRatio = size.X / size.Y
-	c.Position = position
-	c.LookAt = lookAt
-	c.Up = up
-	c.UpdateViewport()
+	v.AspectRatio = size.X / size.Y
+	v.Position = position
+	v.LookAt = lookAt
+	v.Up = up
 
-	return c
+	return v
 }
 
-// Create camera from bouding box
-func NewCameraBounds(bounds pixel.Rect) *Camera {
-	var c = new(Camera)
-	var size = bounds.Size()
-
-	c.Fov = 70
-	c.AspectRatio = size.X / size.Y
-	c.Position = vmath.NewVector3(-2.0, 2.0, 1.0)
-	c.LookAt = vmath.NewVector3(0.0, 0.0, -1.0)
-	c.Up = vmath.NewVector3(0.0, 1.0, 0.0)
-	c.UpdateViewport()
-
-	return c
+// Get the view of the camera.
+func (v *View) GetView() *View {
+	return v
 }
 
-// UpdateViewport camera projection properties.
-func (c *Camera) UpdateViewport() {
+// Orthonormal basis of the view, U points right, V up and W backwards (the camera looks along -W).
+func (v *View) Basis() (*vmath.Vector3, *vmath.Vector3, *vmath.Vector3) {
+	var direction = v.Position.Clone()
+	direction.Sub(v.LookAt)
 
-	var fovRad = c.Fov * (math.Pi / 180.0)
-
-	var halfHeight = math.Tan(fovRad / 2.0)
-	var halfWidth = c.AspectRatio * halfHeight
-
-	var direction = c.Position.Clone()
-	direction.Sub(c.LookAt)
 	var w = direction.UnitVector()
+	var u = vmath.Cross(v.Up, w).UnitVector()
+	var vv = vmath.Cross(w, u)
 
-	var u = vmath.Cross(c.Up, w)
-	var v = vmath.Cross(w, u)
-
-	u.MulScalar(halfWidth)
-	v.MulScalar(halfHeight)
-
-	c.LowerLeftCorner = c.Position.Clone()
-	c.LowerLeftCorner.Sub(u)
-	c.LowerLeftCorner.Sub(v)
-	c.LowerLeftCorner.Sub(w)
-
-	c.Horizontal = u.Clone()
-	c.Horizontal.MulScalar(2.0)
-
-	c.Vertical = v.Clone()
-	c.Vertical.MulScalar(2.0)
+	return u, vv, w
 }
 
-// Get a ray from this camera, from a normalized UV screen coordinate.
-func (c *Camera) GetRay(u float64, v float64) *vmath.Ray {
-	var hor = c.Horizontal.Clone()
-	hor.MulScalar(u)
-
-	var vert = c.Vertical.Clone()
-	vert.MulScalar(v)
-
-	var direction = c.LowerLeftCorner.Clone()
-	direction.Add(hor)
-	direction.Add(vert)
-	direction.Sub(c.Position)
+// Distance between the position and the look at point.
+func (v *View) Distance() float64 {
+	var direction = v.Position.Clone()
+	direction.Sub(v.LookAt)
+	return direction.Length()
+}
 
-	return vmath.NewRay(c.Position, direction)
+// Random time between the shutter open and close times.
+func (v *View) SampleTime() float64 {
+	return v.ShutterOpen + rand.Float64() * (v.ShutterClose - v.ShutterOpen)
 }
 
-// Copy data from another camera object
-func (c *Camera) Copy(o *Camera) {
-	c.Fov = o.Fov
-	c.AspectRatio = o.AspectRatio
-	c.Position.Copy(o.Position)
-	c.LookAt.Copy(o.LookAt)
-	c.Up.Copy(o.Up)
+// Copy data from another view.
+func (v *View) CopyView(o *View) {
+	v.AspectRatio = o.AspectRatio
+	v.Position.Copy(o.Position)
+	v.LookAt.Copy(o.LookAt)
+	v.Up.Copy(o.Up)
+	v.ShutterOpen = o.ShutterOpen
+	v.ShutterClose = o.ShutterClose
 }
 
-// Clone the camera object
-func (o *Camera) Clone() *Camera {
-	var c = new(Camera)
-	c.Fov = o.Fov
-	c.AspectRatio = o.AspectRatio
-	c.Position = o.Position.Clone()
-	c.LookAt = o.LookAt.Clone()
-	c.Up = o.Up.Clone()
-	c.UpdateViewport()
-	return c
-}
\ No newline at end of file
+// Clone the view.
+func (o *View) CloneView() View {
+	var v View
+	v.AspectRatio = o.AspectRatio
+	v.Position = o.Position.Clone()
+	v.LookAt = o.LookAt.Clone()
+	v.Up = o.Up.Clone()
+	v.ShutterOpen = o.ShutterOpen
+	v.ShutterClose = o.ShutterClose
+	return v
+}
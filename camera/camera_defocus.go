@@ -1,7 +1,5 @@
 package camera
 
-import "C"
-
 import (
 	"github.com/faiface/pixel"
 	"gotracer/vmath"
@@ -10,7 +8,7 @@ import (
 
 // Camera defocus is a camera that has support for defocus blur.
 type CameraDefocus struct {
-	Camera
+	PerspectiveCamera
 
 	// Lens radius affects how much the rays can drift from the center.
 	LensRadius float64
@@ -21,6 +19,16 @@ type CameraDefocus struct {
 	// Distance to be in perfect focus of the camera.
 	FocusDistance float64
 
+	// Shape of the aperture, defines the shape of the bokeh. If nil the aperture is circular.
+	Shape ApertureShape
+
+	// Anamorphic squeeze factor, the bokeh is this times taller than wide. 1 for spherical lenses.
+	Squeeze float64
+
+	// Strength of the cat-eye vignetting, zero to disable it.
+	// Rays passing by the edges of the aperture are blocked by the lens barrel towards the border of the image, the bokeh gets a cat-eye shape and the corners get darker.
+	CatEye float64
+
 	U *vmath.Vector3
 	V *vmath.Vector3
 	W *vmath.Vector3
@@ -29,15 +37,11 @@ type CameraDefocus struct {
 // Create camera from bouding box
 func NewCameraDefocus (bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3, fov float64, aperture float64, focusDistance float64) *CameraDefocus {
 	var c = new(CameraDefocus)
-	var size = bounds.Size()
-
+	c.View = *NewView(bounds, position, lookAt, up)
 	c.Aperture = aperture
 	c.FocusDistance = focusDistance
+	c.Squeeze = 1.0
 	c.Fov = fov
-	c.AspectRatio = size.X / size.Y
-	c.Position = position
-	c.LookAt = lookAt
-	c.Up = up
 	c.UpdateViewport()
 
 	return c
@@ -46,18 +50,11 @@ func NewCameraDefocus (bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath
 // Create camera from bouding box
 func NewCameraDefocusBounds(bounds pixel.Rect) *CameraDefocus {
 	var c = new(CameraDefocus)
-	var size = bounds.Size()
-
+	c.View = *NewView(bounds, vmath.NewVector3(-0.15, 0.2, 0.15), vmath.NewVector3(0.0, 0.0, 0.0), vmath.NewVector3(0.0, 1.0, 0.0))
 	c.Fov = 90
-	c.AspectRatio = size.X / size.Y
-	c.Position = vmath.NewVector3(-0.15, 0.2, 0.15)
-	c.LookAt = vmath.NewVector3(0.0, 0.0, 0.0)
-	c.Up = vmath.NewVector3(0.0, 1.0, 0.0)
 	c.Aperture = 0.0
-
-	var direction = c.Position.Clone()
-	direction.Sub(c.LookAt)
-	c.FocusDistance = direction.Length()
+	c.Squeeze = 1.0
+	c.FocusDistance = c.Distance()
 	c.UpdateViewport()
 
 	return c
@@ -70,13 +67,8 @@ func (c *CameraDefocus) UpdateViewport() {
 	var halfHeight = math.Tan(fovRad / 2.0)
 	var halfWidth = c.AspectRatio * halfHeight
 
-	var direction = c.Position.Clone()
-	direction.Sub(c.LookAt)
-
 	c.LensRadius = c.Aperture / 2.0
-	c.W = direction.UnitVector()
-	c.U = vmath.Cross(c.Up, c.W).UnitVector()
-	c.V = vmath.Cross(c.W, c.U)
+	c.U, c.V, c.W = c.Basis()
 
 	var u = c.U.Clone()
 	var v = c.V.Clone()
@@ -96,12 +88,23 @@ func (c *CameraDefocus) UpdateViewport() {
 
 	c.Vertical = v.Clone()
 	c.Vertical.MulScalar(2.0)
+
+	c.shiftViewport()
 }
 
 // Get a ray from this camera, from a normalized UV screen coordinate.
+// Returns nil if the ray is blocked by the lens barrel (cat-eye vignetting).
 func (c *CameraDefocus) GetRay(u float64, v float64) *vmath.Ray {
 
-	var rd = vmath.RandomInUnitDisk()
+	var rd = c.SampleAperture()
+
+	if c.LensRadius > 0 && c.CatEye > 0 && !c.insideBarrel(rd, u, v) {
+		return nil
+	}
+
+	if c.Squeeze > 0 {
+		rd.X /= c.Squeeze
+	}
 	rd.MulScalar(c.LensRadius)
 
 	var offset = c.U.Clone()
@@ -125,30 +128,82 @@ func (c *CameraDefocus) GetRay(u float64, v float64) *vmath.Ray {
 
 	offset.Add(c.Position)
 
-	return vmath.NewRay(offset, direction)
+	return vmath.NewRayTime(offset, direction, c.SampleTime())
+}
+
+// Get the ray casted through the center of the lens for a normalized UV screen coordinate, in the middle of the shutter interval.
+// Used to find the object visible in a point of the screen.
+func (c *CameraDefocus) FocusRay(u float64, v float64) *vmath.Ray {
+	var hor = c.Horizontal.Clone()
+	hor.MulScalar(u)
+
+	var vert = c.Vertical.Clone()
+	vert.MulScalar(v)
+
+	var direction = c.LowerLeftCorner.Clone()
+	direction.Add(hor)
+	direction.Add(vert)
+	direction.Sub(c.Position)
+
+	return vmath.NewRayTime(c.Position.Clone(), direction, (c.ShutterOpen + c.ShutterClose) / 2.0)
+}
+
+// Set the focus distance to place a point in perfect focus.
+// The focus plane is perpendicular to the view direction, returns false if the point is behind the camera.
+func (c *CameraDefocus) FocusAt(point *vmath.Vector3) bool {
+	var direction = point.Clone()
+	direction.Sub(c.Position)
+
+	var distance = -vmath.Dot(direction, c.W)
+	if distance <= 0 {
+		return false
+	}
+
+	c.FocusDistance = distance
+	return true
+}
+
+// Sample a random point of the aperture shape.
+func (c *CameraDefocus) SampleAperture() *vmath.Vector3 {
+	if c.Shape == nil {
+		return vmath.RandomInUnitDisk()
+	}
+	return c.Shape.Sample()
+}
+
+// Check if a point of the aperture is inside of the lens barrel for a normalized UV screen coordinate.
+// The barrel is a unit circle displaced towards the border of the image, proportionally to the distance to the center of the image (one at the corners).
+func (c *CameraDefocus) insideBarrel(point *vmath.Vector3, u float64, v float64) bool {
+	var corner = math.Sqrt(c.AspectRatio * c.AspectRatio + 1.0)
+	var x = point.X - c.CatEye * (2.0 * u - 1.0) * c.AspectRatio / corner
+	var y = point.Y - c.CatEye * (2.0 * v - 1.0) / corner
+
+	return x * x + y * y <= 1.0
 }
 
 // Copy data from another camera object
 func (c *CameraDefocus) Copy(o *CameraDefocus) {
+	c.CopyView(&o.View)
 	c.Fov = o.Fov
-	c.AspectRatio = o.AspectRatio
-	c.Position.Copy(o.Position)
-	c.LookAt.Copy(o.LookAt)
-	c.Up.Copy(o.Up)
+	c.Shift = o.Shift
 	c.Aperture = o.Aperture
 	c.FocusDistance = o.FocusDistance
+	c.Shape = o.Shape
+	c.Squeeze = o.Squeeze
+	c.CatEye = o.CatEye
 }
 
 // Clone the camera object
-func (o *CameraDefocus) Clone() *CameraDefocus {
+func (o *CameraDefocus) Clone() Camera {
 	var c = new(CameraDefocus)
+	c.View = o.CloneView()
 	c.Fov = o.Fov
-	c.AspectRatio = o.AspectRatio
-	c.Position = o.Position.Clone()
-	c.LookAt = o.LookAt.Clone()
-	c.Up = o.Up.Clone()
+	c.Shift = o.Shift
 	c.Aperture = o.Aperture
 	c.FocusDistance = o.FocusDistance
+	c.Shape = o.Shape
+	c.Squeeze = o.Squeeze
+	c.CatEye = o.CatEye
 	c.UpdateViewport()
 	return c
 }
@@ -0,0 +1,81 @@
+package camera
+
+import (
+	"github.com/faiface/pixel"
+	"gotracer/vmath"
+	"math"
+)
+
+// Fisheye camera using the equidistant projection, the distance to the center of the image is proportional to the angle to the view direction.
+// The image circle fits the smallest side of the image, coordinates outside of the circle do not get rays.
+type FisheyeCamera struct {
+	View
+
+	// Field of view covered by the image circle in degrees, up to 360.
+	Fov float64
+
+	// Orthonormal basis of the camera.
+	// Calculated by the UpdateViewport method.
+	U *vmath.Vector3
+	V *vmath.Vector3
+	W *vmath.Vector3
+}
+
+// Create fisheye camera from bouding box.
+func NewFisheyeCamera(bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3, fov float64) *FisheyeCamera {
+	var c = new(FisheyeCamera)
+	c.View = *NewView(bounds, position, lookAt, up)
+	c.Fov = fov
+	c.UpdateViewport()
+
+	return c
+}
+
+// UpdateViewport camera projection properties.
+func (c *FisheyeCamera) UpdateViewport() {
+	c.U, c.V, c.W = c.Basis()
+}
+
+// Get a ray from this camera, from a normalized UV screen coordinate.
+func (c *FisheyeCamera) GetRay(u float64, v float64) *vmath.Ray {
+	var x = 2.0 * u - 1.0
+	var y = 2.0 * v - 1.0
+
+	// Coordinates relative to the radius of the image circle
+	if c.AspectRatio > 1.0 {
+		x *= c.AspectRatio
+	} else {
+		y /= c.AspectRatio
+	}
+
+	var radius = math.Sqrt(x * x + y * y)
+	if radius > 1.0 {
+		return nil
+	}
+
+	var theta = radius * (c.Fov * math.Pi / 180.0) / 2.0
+	var phi = math.Atan2(y, x)
+	var sinTheta = math.Sin(theta)
+
+	var direction = c.U.Clone()
+	direction.MulScalar(sinTheta * math.Cos(phi))
+
+	var vert = c.V.Clone()
+	vert.MulScalar(sinTheta * math.Sin(phi))
+	direction.Add(vert)
+
+	var forward = c.W.Clone()
+	forward.MulScalar(-math.Cos(theta))
+	direction.Add(forward)
+
+	return vmath.NewRayTime(c.Position, direction, c.SampleTime())
+}
+
+// Clone the camera object
+func (o *FisheyeCamera) Clone() Camera {
+	var c = new(FisheyeCamera)
+	c.View = o.CloneView()
+	c.Fov = o.Fov
+	c.UpdateViewport()
+	return c
+}
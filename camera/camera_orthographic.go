@@ -0,0 +1,83 @@
+package camera
+
+import (
+	"github.com/faiface/pixel"
+	"gotracer/vmath"
+)
+
+// Orthographic camera casts parallel rays from a rectangle centered at the camera position.
+// Objects keep their size independently of the distance to the camera, used for elevations and plans.
+type OrthographicCamera struct {
+	View
+
+	// Height of the area seen by the camera in world units, the width is calculated from the aspect ratio.
+	Height float64
+
+	// Lower left corner of the rectangle where the rays start.
+	// Calculated by the UpdateViewport method.
+	LowerLeftCorner *vmath.Vector3
+
+	// Vertical and horizontal size of the rectangle.
+	// Calculated by the UpdateViewport method.
+	Vertical *vmath.Vector3
+	Horizontal *vmath.Vector3
+
+	// Direction of all rays casted by the camera.
+	// Calculated by the UpdateViewport method.
+	Direction *vmath.Vector3
+}
+
+// Create orthographic camera from bouding box.
+func NewOrthographicCamera(bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3, height float64) *OrthographicCamera {
+	var c = new(OrthographicCamera)
+	c.View = *NewView(bounds, position, lookAt, up)
+	c.Height = height
+	c.UpdateViewport()
+
+	return c
+}
+
+// UpdateViewport camera projection properties.
+func (c *OrthographicCamera) UpdateViewport() {
+	var u, v, w = c.Basis()
+
+	u.MulScalar(c.Height * c.AspectRatio / 2.0)
+	v.MulScalar(c.Height / 2.0)
+
+	c.LowerLeftCorner = c.Position.Clone()
+	c.LowerLeftCorner.Sub(u)
+	c.LowerLeftCorner.Sub(v)
+
+	c.Horizontal = u.Clone()
+	c.Horizontal.MulScalar(2.0)
+
+	c.Vertical = v.Clone()
+	c.Vertical.MulScalar(2.0)
+
+	c.Direction = w.Clone()
+	c.Direction.MulScalar(-1.0)
+}
+
+// Get a ray from this camera, from a normalized UV screen coordinate.
+func (c *OrthographicCamera) GetRay(u float64, v float64) *vmath.Ray {
+	var hor = c.Horizontal.Clone()
+	hor.MulScalar(u)
+
+	var vert = c.Vertical.Clone()
+	vert.MulScalar(v)
+
+	var origin = c.LowerLeftCorner.Clone()
+	origin.Add(hor)
+	origin.Add(vert)
+
+	return vmath.NewRayTime(origin, c.Direction.Clone(), c.SampleTime())
+}
+
+// Clone the camera object
+func (o *OrthographicCamera) Clone() Camera {
+	var c = new(OrthographicCamera)
+	c.View = o.CloneView()
+	c.Height = o.Height
+	c.UpdateViewport()
+	return c
+}
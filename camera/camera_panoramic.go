@@ -0,0 +1,100 @@
+package camera
+
+import (
+	"github.com/faiface/pixel"
+	"gotracer/vmath"
+	"math"
+)
+
+// Panoramic camera using the latitude-longitude (equirectangular) projection, covers all directions around the camera.
+// The horizontal axis of the image is the longitude (360 degrees) and the vertical axis the latitude (180 degrees), the center of the image is the look direction.
+// Images should have a 2:1 aspect ratio to avoid stretching.
+type PanoramicCamera struct {
+	View
+
+	// Distance of the eye to the camera position for omnidirectional stereo (ODS), negative for the left eye and positive for the right eye.
+	// The rays start in a circle around the position, tangent to the viewing direction. Zero for a single point of view.
+	EyeOffset float64
+
+	// Distance where the rays of both eyes converge (toe-in), zero for parallel rays.
+	ConvergenceDistance float64
+
+	// Orthonormal basis of the camera.
+	// Calculated by the UpdateViewport method.
+	U *vmath.Vector3
+	V *vmath.Vector3
+	W *vmath.Vector3
+}
+
+// Create panoramic camera from bouding box.
+func NewPanoramicCamera(bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3) *PanoramicCamera {
+	var c = new(PanoramicCamera)
+	c.View = *NewView(bounds, position, lookAt, up)
+	c.UpdateViewport()
+
+	return c
+}
+
+// UpdateViewport camera projection properties.
+func (c *PanoramicCamera) UpdateViewport() {
+	c.U, c.V, c.W = c.Basis()
+}
+
+// Get a ray from this camera, from a normalized UV screen coordinate.
+func (c *PanoramicCamera) GetRay(u float64, v float64) *vmath.Ray {
+	var direction = c.Direction(u, v)
+
+	if c.EyeOffset == 0 {
+		return vmath.NewRayTime(c.Position, direction, c.SampleTime())
+	}
+
+	// The eye is moved to the side of the horizontal viewing direction
+	var longitude = (u - 0.5) * 2.0 * math.Pi
+
+	var origin = c.U.Clone()
+	origin.MulScalar(c.EyeOffset * math.Cos(longitude))
+
+	var side = c.W.Clone()
+	side.MulScalar(c.EyeOffset * math.Sin(longitude))
+	origin.Add(side)
+	origin.Add(c.Position)
+
+	// Rotate the ray towards the point in the viewing direction at the convergence distance
+	if c.ConvergenceDistance > 0 {
+		direction.MulScalar(c.ConvergenceDistance)
+		direction.Add(c.Position)
+		direction.Sub(origin)
+	}
+
+	return vmath.NewRayTime(origin, direction, c.SampleTime())
+}
+
+// Direction of the ray casted for a normalized UV screen coordinate.
+func (c *PanoramicCamera) Direction(u float64, v float64) *vmath.Vector3 {
+	var longitude = (u - 0.5) * 2.0 * math.Pi
+	var latitude = (v - 0.5) * math.Pi
+	var cosLatitude = math.Cos(latitude)
+
+	var direction = c.U.Clone()
+	direction.MulScalar(cosLatitude * math.Sin(longitude))
+
+	var vert = c.V.Clone()
+	vert.MulScalar(math.Sin(latitude))
+	direction.Add(vert)
+
+	var forward = c.W.Clone()
+	forward.MulScalar(-cosLatitude * math.Cos(longitude))
+	direction.Add(forward)
+
+	return direction
+}
+
+// Clone the camera object
+func (o *PanoramicCamera) Clone() Camera {
+	var c = new(PanoramicCamera)
+	c.View = o.CloneView()
+	c.EyeOffset = o.EyeOffset
+	c.ConvergenceDistance = o.ConvergenceDistance
+	c.UpdateViewport()
+	return c
+}
@@ -0,0 +1,113 @@
+package camera
+
+import (
+	"github.com/faiface/pixel"
+	"gotracer/vmath"
+	"math"
+)
+
+// Perspective camera projects the objects into a plane in front of a pinhole at the camera position.
+type PerspectiveCamera struct {
+	View
+
+	// Vertical field of view of the camera in degrees.
+	Fov float64
+
+	// Horizontal lens shift relative to the viewport width.
+	// Moves the image without rotating the camera, used for off-axis stereo projections.
+	Shift float64
+
+	// The Lower left corner of the camera relative to the center considering the vertical and horizontal sizes.
+	// Calculated by the UpdateViewport method.
+	LowerLeftCorner *vmath.Vector3
+
+	// Vertical size of the camera (usually only uses Y).
+	// Calculated by the UpdateViewport method.
+	Vertical *vmath.Vector3
+
+	// Horizontal size of the camera (usually only uses X).
+	// Calculated by the UpdateViewport method.
+	Horizontal *vmath.Vector3
+}
+
+// Create camera from bouding box
+func NewPerspectiveCamera(bounds pixel.Rect, position *vmath.Vector3, lookAt *vmath.Vector3, up *vmath.Vector3, fov float64) *PerspectiveCamera {
+	var c = new(PerspectiveCamera)
+	c.View = *NewView(bounds, position, lookAt, up)
+	c.Fov = fov
+	c.UpdateViewport()
+
+	return c
+}
+
+// Create camera from bouding box
+func NewPerspectiveCameraBounds(bounds pixel.Rect) *PerspectiveCamera {
+	return NewPerspectiveCamera(bounds, vmath.NewVector3(-2.0, 2.0, 1.0), vmath.NewVector3(0.0, 0.0, -1.0), vmath.NewVector3(0.0, 1.0, 0.0), 70)
+}
+
+// UpdateViewport camera projection properties.
+func (c *PerspectiveCamera) UpdateViewport() {
+
+	var fovRad = c.Fov * (math.Pi / 180.0)
+
+	var halfHeight = math.Tan(fovRad / 2.0)
+	var halfWidth = c.AspectRatio * halfHeight
+
+	var u, v, w = c.Basis()
+
+	u.MulScalar(halfWidth)
+	v.MulScalar(halfHeight)
+
+	c.LowerLeftCorner = c.Position.Clone()
+	c.LowerLeftCorner.Sub(u)
+	c.LowerLeftCorner.Sub(v)
+	c.LowerLeftCorner.Sub(w)
+
+	c.Horizontal = u.Clone()
+	c.Horizontal.MulScalar(2.0)
+
+	c.Vertical = v.Clone()
+	c.Vertical.MulScalar(2.0)
+
+	c.shiftViewport()
+}
+
+// Move the viewport by the lens shift.
+func (c *PerspectiveCamera) shiftViewport() {
+	var shift = c.Horizontal.Clone()
+	shift.MulScalar(c.Shift)
+	c.LowerLeftCorner.Add(shift)
+}
+
+// Get a ray from this camera, from a normalized UV screen coordinate.
+func (c *PerspectiveCamera) GetRay(u float64, v float64) *vmath.Ray {
+	var hor = c.Horizontal.Clone()
+	hor.MulScalar(u)
+
+	var vert = c.Vertical.Clone()
+	vert.MulScalar(v)
+
+	var direction = c.LowerLeftCorner.Clone()
+	direction.Add(hor)
+	direction.Add(vert)
+	direction.Sub(c.Position)
+
+	return vmath.NewRayTime(c.Position, direction, c.SampleTime())
+}
+
+// Copy data from another camera object
+func (c *PerspectiveCamera) Copy(o *PerspectiveCamera) {
+	c.CopyView(&o.View)
+	c.Fov = o.Fov
+	c.Shift = o.Shift
+}
+
+// Clone the camera object
+func (o *PerspectiveCamera) Clone() Camera {
+	var c = new(PerspectiveCamera)
+	c.View = o.CloneView()
+	c.Fov = o.Fov
+	c.Shift = o.Shift
+	c.UpdateViewport()
+	return c
+}
@@ -0,0 +1,166 @@
+package camera
+
+import (
+	"gotracer/vmath"
+	"math"
+)
+
+// How the views of both eyes converge.
+type StereoConvergence int
+
+const (
+	// Eyes look in parallel directions, the images are shifted to converge at the convergence distance (asymmetric frustums).
+	StereoOffAxis StereoConvergence = iota
+
+	// Eyes are rotated to look at the point at the convergence distance.
+	StereoToeIn
+)
+
+// How the views of both eyes are placed in the image.
+type StereoLayout int
+
+const (
+	// Left eye in the left half of the image and right eye in the right half.
+	StereoSideBySide StereoLayout = iota
+
+	// Left eye in the top half of the image and right eye in the bottom half.
+	StereoOverUnder
+)
+
+// Stereo camera rig, renders the view of the left and right eyes into the same image.
+// The eyes are placed at both sides of a center camera, that is used for the position, orientation and projection of the rig.
+// Panoramic center cameras render omnidirectional stereo (ODS), other cameras move the eyes along the horizontal axis of the view.
+type StereoCamera struct {
+	// Center camera of the rig, the aspect ratio is the one of the whole image.
+	Camera Camera
+
+	// Distance between the eyes (interocular distance) in world units.
+	EyeSeparation float64
+
+	// Distance to the camera where the views of both eyes converge (zero parallax).
+	ConvergenceDistance float64
+
+	Convergence StereoConvergence
+	Layout StereoLayout
+
+	// Cameras of each eye.
+	// Calculated by the UpdateViewport method.
+	Left Camera
+	Right Camera
+}
+
+// Create stereo camera rig from a center camera.
+// The eyes converge at the look at point of the center camera.
+func NewStereoCamera(camera Camera, eyeSeparation float64, convergence StereoConvergence, layout StereoLayout) *StereoCamera {
+	var c = new(StereoCamera)
+	c.Camera = camera
+	c.EyeSeparation = eyeSeparation
+	c.ConvergenceDistance = camera.GetView().Distance()
+	c.Convergence = convergence
+	c.Layout = layout
+	c.UpdateViewport()
+
+	return c
+}
+
+// UpdateViewport of the center camera and recreate the eye cameras.
+func (c *StereoCamera) UpdateViewport() {
+	c.Camera.UpdateViewport()
+	c.Left = c.eye(-1.0)
+	c.Right = c.eye(1.0)
+}
+
+// Create the camera of a eye, the side is -1 for the left eye and 1 for the right eye.
+func (c *StereoCamera) eye(side float64) Camera {
+	var center = c.Camera.GetView()
+	var offset = side * c.EyeSeparation / 2.0
+
+	var eye = c.Camera.Clone()
+	var view = eye.GetView()
+
+	// Each eye uses half of the image
+	if c.Layout == StereoSideBySide {
+		view.AspectRatio = center.AspectRatio / 2.0
+	} else {
+		view.AspectRatio = center.AspectRatio * 2.0
+	}
+
+	if panoramic, ok := eye.(*PanoramicCamera); ok {
+		panoramic.EyeOffset = offset
+		panoramic.ConvergenceDistance = 0.0
+		if c.Convergence == StereoToeIn {
+			panoramic.ConvergenceDistance = c.ConvergenceDistance
+		}
+
+		eye.UpdateViewport()
+		return eye
+	}
+
+	var u, _, w = center.Basis()
+	u.MulScalar(offset)
+	view.Position.Add(u)
+
+	if c.Convergence == StereoToeIn {
+		w.MulScalar(-c.ConvergenceDistance)
+		view.LookAt.Copy(center.Position)
+		view.LookAt.Add(w)
+	} else {
+		view.LookAt.Add(u)
+
+		// The viewport is shifted to the opposite side of the eye, to match the center view at the convergence distance
+		var perspective *PerspectiveCamera
+		switch camera := eye.(type) {
+		case *PerspectiveCamera:
+			perspective = camera
+		case *CameraDefocus:
+			perspective = &camera.PerspectiveCamera
+		}
+
+		if perspective != nil {
+			var halfWidth = view.AspectRatio * math.Tan(perspective.Fov * (math.Pi / 180.0) / 2.0)
+			perspective.Shift -= offset / (2.0 * halfWidth * c.ConvergenceDistance)
+		}
+	}
+
+	eye.UpdateViewport()
+	return eye
+}
+
+// Get a ray from this camera, from a normalized UV screen coordinate of the whole image.
+func (c *StereoCamera) GetRay(u float64, v float64) *vmath.Ray {
+	var eye, eu, ev = c.EyeCoordinate(u, v)
+	return eye.GetRay(eu, ev)
+}
+
+// Convert a normalized UV screen coordinate of the whole image into the camera and coordinate of the eye view that covers it.
+func (c *StereoCamera) EyeCoordinate(u float64, v float64) (Camera, float64, float64) {
+	if c.Layout == StereoSideBySide {
+		if u < 0.5 {
+			return c.Left, u * 2.0, v
+		}
+		return c.Right, u * 2.0 - 1.0, v
+	}
+
+	// The V coordinate grows towards the top of the image
+	if v >= 0.5 {
+		return c.Left, u, v * 2.0 - 1.0
+	}
+	return c.Right, u, v * 2.0
+}
+
+// Get the view of the center camera.
+func (c *StereoCamera) GetView() *View {
+	return c.Camera.GetView()
+}
+
+// Clone the camera object
+func (o *StereoCamera) Clone() Camera {
+	var c = new(StereoCamera)
+	c.Camera = o.Camera.Clone()
+	c.EyeSeparation = o.EyeSeparation
+	c.ConvergenceDistance = o.ConvergenceDistance
+	c.Convergence = o.Convergence
+	c.Layout = o.Layout
+	c.UpdateViewport()
+	return c
+}
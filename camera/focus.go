@@ -0,0 +1,37 @@
+package camera
+
+import (
+	"gotracer/geometry"
+	"gotracer/material"
+	"math"
+)
+
+// Focus a camera at the surface visible in a normalized UV screen coordinate (focus query).
+// A ray is casted through the center of the lens, the focus distance is set to the distance of the first surface hit, the viewport is updated.
+// Stereo rigs focus their center camera at the surface seen by the eye that covers the coordinate.
+// Returns false if the camera has no defocus blur or no surface was found.
+func Focus(cam Camera, scene *geometry.Scene, u float64, v float64) bool {
+	var center = cam
+	var target = cam
+	if stereo, ok := cam.(*StereoCamera); ok {
+		center = stereo.Camera
+		target, u, v = stereo.EyeCoordinate(u, v)
+	}
+
+	var defocus, ok = center.(*CameraDefocus)
+	if !ok {
+		return false
+	}
+
+	// Eyes of stereo rigs are clones of the center camera
+	var eye = target.(*CameraDefocus)
+	var ray = eye.FocusRay(u, v)
+	var hitRecord = material.NewHitRecord()
+
+	if !scene.HitSurface(ray, 0.0, math.MaxFloat64, hitRecord) || !defocus.FocusAt(hitRecord.P) {
+		return false
+	}
+
+	cam.UpdateViewport()
+	return true
+}
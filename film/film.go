@@ -0,0 +1,61 @@
+package film
+
+import (
+	"gotracer/vmath"
+)
+
+// Film stores the linear radiance of the rendered image in floating point precision.
+// Samples are accumulated into the film, the color of each pixel is the average of its samples.
+// Radiance values above 1.0 are preserved and can be exported to high dynamic range formats.
+type Film struct {
+	// Width of the film in pixels.
+	Width int
+
+	// Height of the film in pixels.
+	Height int
+
+	// Sum of the radiance samples of each pixel.
+	// Pixels are stored row by row starting from the bottom row (same as the camera UV coordinates).
+	Pix []vmath.Vector3
+
+	// Number of samples accumulated for each pixel.
+	Samples int
+}
+
+// Create new film with a resolution.
+func NewFilm(width int, height int) *Film {
+	var f = new(Film)
+	f.Width = width
+	f.Height = height
+	f.Pix = make([]vmath.Vector3, width * height)
+	f.Samples = 0
+	return f
+}
+
+// Index of a pixel in the Pix array, y starts from the bottom of the image.
+func (f *Film) Index(x int, y int) int {
+	return y * f.Width + x
+}
+
+// Add a radiance sample to a pixel.
+// The sample count of the film is not incremented, it should be incremented after each pixel received a new sample.
+func (f *Film) AddSample(x int, y int, color *vmath.Vector3) {
+	f.Pix[f.Index(x, y)].Add(color)
+}
+
+// Get the average radiance of a pixel.
+func (f *Film) Color(x int, y int) *vmath.Vector3 {
+	var c = f.Pix[f.Index(x, y)].Clone()
+	if f.Samples > 0 {
+		c.DivideScalar(float64(f.Samples))
+	}
+	return c
+}
+
+// Clear all samples stored in the film.
+func (f *Film) Clear() {
+	for i := 0; i < len(f.Pix); i++ {
+		f.Pix[i].Set(0.0, 0.0, 0.0)
+	}
+	f.Samples = 0
+}
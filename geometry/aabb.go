@@ -0,0 +1,133 @@
+package geometry
+
+import (
+	"gotracer/vmath"
+	"math"
+)
+
+// Axis aligned bounding box, used to quickly discard rays that do not intersect objects.
+// Unlike the Box object it has no material and cannot be rendered.
+type AABB struct {
+	// Minimum corner of the bounding box
+	Min *vmath.Vector3
+
+	// Maximum corner of the bounding box
+	Max *vmath.Vector3
+}
+
+// Create new bounding box from its corners.
+func NewAABB(min *vmath.Vector3, max *vmath.Vector3) *AABB {
+	var box = new(AABB)
+	box.Min = min
+	box.Max = max
+	return box
+}
+
+// Create new empty bounding box, that can be expanded to contain other boxes.
+func NewEmptyAABB() *AABB {
+	var box = new(AABB)
+	box.Min = vmath.NewVector3(math.Inf(1), math.Inf(1), math.Inf(1))
+	box.Max = vmath.NewVector3(math.Inf(-1), math.Inf(-1), math.Inf(-1))
+	return box
+}
+
+// Set the corners of the box.
+// Internally copies the value of the vectors passed as parameters.
+func (box *AABB) Set(min *vmath.Vector3, max *vmath.Vector3) {
+	box.Min.Copy(min)
+	box.Max.Copy(max)
+}
+
+// Expand the box to also contain another box.
+func (box *AABB) Expand(b *AABB) {
+	box.Min.Min(b.Min)
+	box.Max.Max(b.Max)
+}
+
+// Expand the box to also contain a point.
+func (box *AABB) ExpandPoint(p *vmath.Vector3) {
+	box.Min.Min(p)
+	box.Max.Max(p)
+}
+
+// Center point of the box.
+func (box *AABB) Center() *vmath.Vector3 {
+	var center = box.Min.Clone()
+	center.Add(box.Max)
+	center.MulScalar(0.5)
+	return center
+}
+
+// Surface area of the box, used for the surface area heuristic.
+// Empty boxes have zero area.
+func (box *AABB) SurfaceArea() float64 {
+	var dx = box.Max.X - box.Min.X
+	var dy = box.Max.Y - box.Min.Y
+	var dz = box.Max.Z - box.Min.Z
+
+	if dx < 0 || dy < 0 || dz < 0 {
+		return 0.0
+	}
+
+	return 2.0 * (dx * dy + dy * dz + dz * dx)
+}
+
+// Axis where the box is longer (0 for X, 1 for Y and 2 for Z).
+func (box *AABB) LongestAxis() int {
+	var dx = box.Max.X - box.Min.X
+	var dy = box.Max.Y - box.Min.Y
+	var dz = box.Max.Z - box.Min.Z
+
+	if dx > dy && dx > dz {
+		return 0
+	} else if dy > dz {
+		return 1
+	}
+	return 2
+}
+
+// Check if the ray intersects the box between tmin and tmax, using the slab method.
+func (box *AABB) Hit(ray *vmath.Ray, tmin float64, tmax float64) bool {
+	var _, _, hit = box.Intersect(ray, tmin, tmax)
+	return hit
+}
+
+// Calculate the part of the ray between tmin and tmax that is inside of the box.
+// Returns the distances where the ray enters and leaves the box, false if the ray does not intersect the box.
+func (box *AABB) Intersect(ray *vmath.Ray, tmin float64, tmax float64) (float64, float64, bool) {
+	for axis := 0; axis < 3; axis++ {
+		var invDirection = 1.0 / ray.Direction.Component(axis)
+		var origin = ray.Origin.Component(axis)
+		var t0 = (box.Min.Component(axis) - origin) * invDirection
+		var t1 = (box.Max.Component(axis) - origin) * invDirection
+
+		if invDirection < 0.0 {
+			var temp = t0
+			t0 = t1
+			t1 = temp
+		}
+
+		if t0 > tmin {
+			tmin = t0
+		}
+		if t1 < tmax {
+			tmax = t1
+		}
+		if tmax < tmin {
+			return 0, 0, false
+		}
+	}
+
+	return tmin, tmax, true
+}
+
+// Copy the content of another box to this one.
+func (box *AABB) Copy(b *AABB) {
+	box.Min.Copy(b.Min)
+	box.Max.Copy(b.Max)
+}
+
+// Clone the bounding box.
+func (box *AABB) Clone() *AABB {
+	return NewAABB(box.Min.Clone(), box.Max.Clone())
+}
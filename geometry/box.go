@@ -3,6 +3,8 @@ package geometry
 import (
 	"gotracer/material"
 	"gotracer/vmath"
+	"math"
+	"math/rand"
 )
 
 // Box hitable object.
@@ -26,90 +28,251 @@ func NewBox(min *vmath.Vector3, max *vmath.Vector3, material material.Material)
 }
 
 func (box *Box) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var tnear, tfar, nearNormal, farNormal, ok = box.slabs(ray)
+	if !ok {
+		return false
+	}
+
+	// If the ray starts inside of the box the surface hit is where it leaves the box
+	var t, normal = tnear, nearNormal
+	if t <= tmin {
+		t, normal = tfar, farNormal
+	}
+	if t <= tmin || t >= tmax {
+		return false
+	}
+
+	box.setHitRecord(ray, t, normal, hitRecord)
+	return true
+}
 
-	var txmin = (box.Min.X - ray.Origin.X) / ray.Direction.X
-	var txmax = (box.Max.X - ray.Origin.X) / ray.Direction.X
+// Intersect the line of the ray with the slabs of the box.
+// Returns the distances where the ray enters and leaves the box and the normals of the faces crossed.
+func (box *Box) slabs(ray *vmath.Ray) (float64, float64, *vmath.Vector3, *vmath.Vector3, bool) {
+	var tnear = math.Inf(-1)
+	var tfar = math.Inf(1)
+	var nearAxis, farAxis = 0, 0
+	var nearSide, farSide = -1.0, 1.0
 
-	var normal = vmath.NewVector3(0, 1.0, 0)
-	var signal = -1.0
+	for axis := 0; axis < 3; axis++ {
+		var origin = ray.Origin.Component(axis)
+		var t0 = (box.Min.Component(axis) - origin) / ray.Direction.Component(axis)
+		var t1 = (box.Max.Component(axis) - origin) / ray.Direction.Component(axis)
+		var side = -1.0
 
-	if txmin > txmax {
-		var temp = txmin
-		txmin = txmax
-		txmax = temp
-		signal = 1.0
+		if t0 > t1 {
+			var temp = t0
+			t0 = t1
+			t1 = temp
+			side = 1.0
+		}
+
+		if t0 > tnear {
+			tnear = t0
+			nearAxis = axis
+			nearSide = side
+		}
+		if t1 < tfar {
+			tfar = t1
+			farAxis = axis
+			farSide = -side
+		}
 	}
 
-	if (tmin > txmax) || (txmin > tmax) {
-		return false
+	if tnear > tfar {
+		return 0, 0, nil, nil, false
 	}
 
-	if txmin > tmin {
-		normal.Set(signal, 0.0, 0.0)
-		tmin = txmin
+	return tnear, tfar, axisNormal(nearAxis, nearSide), axisNormal(farAxis, farSide), true
+}
+
+// Create a normal along one of the axis.
+func axisNormal(axis int, side float64) *vmath.Vector3 {
+	var normal = vmath.NewVector3(0.0, 0.0, 0.0)
+	switch axis {
+	case 0:
+		normal.X = side
+	case 1:
+		normal.Y = side
+	default:
+		normal.Z = side
 	}
-	if txmax < tmax {
-		tmax = txmax
+	return normal
+}
+
+// Fill the hit record with the surface of the box at a distance along the ray.
+func (box *Box) setHitRecord(ray *vmath.Ray, t float64, normal *vmath.Vector3, hitRecord *material.HitRecord) {
+	hitRecord.Material = box.Material
+	hitRecord.T = t
+	hitRecord.P = ray.PointAtParameter(t)
+	hitRecord.Normal = normal
+	hitRecord.FrontFace = vmath.Dot(ray.Direction, normal) < 0
+	hitRecord.U, hitRecord.V = box.UV(hitRecord.P, normal)
+}
+
+// The ray is inside of the box between the slabs.
+func (box *Box) Intervals(ray *vmath.Ray) []*Interval {
+	var tnear, tfar, nearNormal, farNormal, ok = box.slabs(ray)
+	if !ok {
+		return nil
 	}
 
-	var tymin = (box.Min.Y - ray.Origin.Y) / ray.Direction.Y
-	var tymax = (box.Max.Y - ray.Origin.Y) / ray.Direction.Y
-	signal = -1.0
+	var enter = material.NewHitRecord()
+	box.setHitRecord(ray, tnear, nearNormal, enter)
+
+	var exit = material.NewHitRecord()
+	box.setHitRecord(ray, tfar, farNormal, exit)
 
-	if tymin > tymax {
-		var temp = tymin
-		tymin = tymax
-		tymax = temp
-		signal = 1.0
+	return []*Interval{NewInterval(enter, exit)}
+}
+
+// Calculate the texture coordinates of a point in the surface of the box.
+// Each face is mapped to the full texture, selected by the normal of the face.
+func (box *Box) UV(p *vmath.Vector3, normal *vmath.Vector3) (float64, float64) {
+	var size = box.Max.Clone()
+	size.Sub(box.Min)
+
+	var local = p.Clone()
+	local.Sub(box.Min)
+
+	var u, v float64
+
+	if normal.X != 0 {
+		u = local.Z / size.Z
+		v = local.Y / size.Y
+		if normal.X > 0 {
+			u = 1.0 - u
+		}
+	} else if normal.Y != 0 {
+		u = local.X / size.X
+		v = local.Z / size.Z
+		if normal.Y > 0 {
+			v = 1.0 - v
+		}
+	} else {
+		u = local.X / size.X
+		v = local.Y / size.Y
+		if normal.Z < 0 {
+			u = 1.0 - u
+		}
 	}
 
-	if (tmin > tymax) || (tymin > tmax) {
-		return false
+	return clampUV(u), clampUV(v)
+}
+
+// Clamp a texture coordinate to the [0, 1] range, degenerated boxes produce NaN coordinates that are mapped to 0.
+func clampUV(v float64) float64 {
+	if v > 1.0 {
+		return 1.0
+	} else if v > 0.0 {
+		return v
 	}
+	return 0.0
+}
+
+func (box *Box) BoundingBox(aabb *AABB) bool {
+	aabb.Set(box.Min, box.Max)
+	return true
+}
+
+func (box *Box) Emissive() bool {
+	return material.IsEmissive(box.Material)
+}
 
-	if tymin > tmin {
-		normal.Set(0.0, signal, 0.0)
-		tmin = tymin
+// Box faces visible from the origin, with their areas.
+// Faces are identified by the axis and the side (-1 for the Min side, 1 for the Max side).
+func (box *Box) visibleFaces(origin *vmath.Vector3) (axes []int, sides []float64, areas []float64) {
+	var size = box.Max.Clone()
+	size.Sub(box.Min)
+
+	for axis := 0; axis < 3; axis++ {
+		var area = size.Component((axis + 1) % 3) * size.Component((axis + 2) % 3)
+
+		if origin.Component(axis) < box.Min.Component(axis) {
+			axes = append(axes, axis)
+			sides = append(sides, -1.0)
+			areas = append(areas, area)
+		} else if origin.Component(axis) > box.Max.Component(axis) {
+			axes = append(axes, axis)
+			sides = append(sides, 1.0)
+			areas = append(areas, area)
+		}
 	}
-	if tymax < tmax {
-		tmax = tymax
+
+	return axes, sides, areas
+}
+
+// Points are sampled uniformly over the faces of the box that are visible from the origin.
+// If the origin is inside of the box every direction hits it, directions are sampled uniformly.
+func (box *Box) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var axes, sides, areas = box.visibleFaces(origin)
+
+	var total = 0.0
+	for i := 0; i < len(areas); i++ {
+		total += areas[i]
 	}
 
-	var tzmin = (box.Min.Z - ray.Origin.Z) / ray.Direction.Z
-	var tzmax = (box.Max.Z - ray.Origin.Z) / ray.Direction.Z
-	signal = -1.0
+	if len(axes) == 0 || total <= 0 {
+		return vmath.RandomUnitVector()
+	}
 
-	if tzmin > tzmax {
-		var temp = tzmin
-		tzmin = tzmax
-		tzmax = temp
-		signal = 1.0
+	// Select a face with probability proportional to its area
+	var face = len(areas) - 1
+	var r = rand.Float64() * total
+	for i := 0; i < len(areas); i++ {
+		if r < areas[i] {
+			face = i
+			break
+		}
+		r -= areas[i]
 	}
 
-	if (tmin > tzmax) || (tzmin > tmax) {
-		return false
+	var point = vmath.NewVector3(box.Min.X + rand.Float64() * (box.Max.X - box.Min.X), box.Min.Y + rand.Float64() * (box.Max.Y - box.Min.Y), box.Min.Z + rand.Float64() * (box.Max.Z - box.Min.Z))
+
+	var value = box.Min.Component(axes[face])
+	if sides[face] > 0 {
+		value = box.Max.Component(axes[face])
 	}
 
-	if tzmin > tmin {
-		normal.Set(0.0, 0.0, signal)
-		tmin = tzmin
+	if axes[face] == 0 {
+		point.X = value
+	} else if axes[face] == 1 {
+		point.Y = value
+	} else {
+		point.Z = value
 	}
-	if tzmax < tmax {
-		tmax = tzmax
+
+	point.Sub(origin)
+	return point
+}
+
+// The area density is converted to solid angle using the distance and the angle of the face hit.
+func (box *Box) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var _, _, areas = box.visibleFaces(origin)
+
+	var total = 0.0
+	for i := 0; i < len(areas); i++ {
+		total += areas[i]
 	}
 
-	if tmin > tmax {
-		var temp = tmin
-		tmin = tmax
-		tmax = temp
+	if len(areas) == 0 || total <= 0 {
+		return 1.0 / (4.0 * math.Pi)
 	}
 
-	hitRecord.Material = box.Material
-	hitRecord.T = tmin
-	hitRecord.P = ray.PointAtParameter(hitRecord.T)
-	hitRecord.Normal = normal
+	var hitRecord = material.NewHitRecord()
+	if !box.Hit(vmath.NewRay(origin, direction), 0.0, math.MaxFloat64, hitRecord) {
+		return 0.0
+	}
 
-	return true
+	var length = direction.Length()
+	var distanceSq = hitRecord.T * hitRecord.T * length * length
+	var cosine = math.Abs(vmath.Dot(direction, hitRecord.Normal)) / length
+
+	if cosine <= 0 {
+		return 0.0
+	}
+
+	return distanceSq / (cosine * total)
 }
 
 func (o *Box) Clone() Hitable {
@@ -0,0 +1,245 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+)
+
+// Maximum number of objects that can be stored in a single leaf node.
+const BVHMaxLeafSize = 4
+
+// Number of buckets used to evaluate the surface area heuristic split candidates.
+const BVHBuckets = 12
+
+// Cost of traversing a node relative to the cost of intersecting a object.
+const BVHTraversalCost = 0.125
+
+// Bounding volume hierarchy node, stores the objects in a tree of bounding boxes.
+// Rays only test the objects inside of the boxes that they intersect, this avoids testing every object in the scene.
+// The tree is built using the surface area heuristic (SAH) to choose where to split the objects.
+type BVHNode struct {
+	// Bounding box that contains all objects inside of this node.
+	Box *AABB
+
+	// Axis used to split the node children (0 for X, 1 for Y and 2 for Z).
+	Axis int
+
+	// Left child node, contains the objects before the split.
+	Left *BVHNode
+
+	// Right child node, contains the objects after the split.
+	Right *BVHNode
+
+	// Objects stored in this node, only leaf nodes store objects.
+	List []Hitable
+}
+
+// Object stored in the BVH with its precomputed bounding box and center.
+type bvhObject struct {
+	Object Hitable
+	Box *AABB
+	Center *vmath.Vector3
+}
+
+// Bucket used to evaluate the cost of split candidates.
+type bvhBucket struct {
+	Count int
+	Box *AABB
+}
+
+// Build a new BVH tree from a list of objects.
+// Objects without bounding box (infinite objects) cannot be stored in the tree and are ignored.
+func NewBVH(list []Hitable) *BVHNode {
+	var objects []*bvhObject
+
+	for i := 0; i < len(list); i++ {
+		var box = NewEmptyAABB()
+		if list[i].BoundingBox(box) {
+			var o = new(bvhObject)
+			o.Object = list[i]
+			o.Box = box
+			o.Center = box.Center()
+			objects = append(objects, o)
+		}
+	}
+
+	return buildBVH(objects)
+}
+
+// Recursively build the BVH nodes from a list of objects.
+func buildBVH(objects []*bvhObject) *BVHNode {
+	var node = new(BVHNode)
+	node.Box = NewEmptyAABB()
+
+	var centers = NewEmptyAABB()
+
+	for i := 0; i < len(objects); i++ {
+		node.Box.Expand(objects[i].Box)
+		centers.ExpandPoint(objects[i].Center)
+	}
+
+	if len(objects) <= 1 {
+		return createBVHLeaf(node, objects)
+	}
+
+	var axis = centers.LongestAxis()
+	var cmin = centers.Min.Component(axis)
+	var extent = centers.Max.Component(axis) - cmin
+
+	node.Axis = axis
+
+	// All centers in the same point, the objects cannot be split spatially
+	if extent <= 0 {
+		if len(objects) <= BVHMaxLeafSize {
+			return createBVHLeaf(node, objects)
+		}
+
+		var half = len(objects) / 2
+		node.Left = buildBVH(objects[:half])
+		node.Right = buildBVH(objects[half:])
+		return node
+	}
+
+	// Distribute the objects into buckets along the axis
+	var buckets [BVHBuckets]bvhBucket
+	for i := 0; i < BVHBuckets; i++ {
+		buckets[i].Box = NewEmptyAABB()
+	}
+
+	var bucketIndex = func(o *bvhObject) int {
+		var b = int(BVHBuckets * (o.Center.Component(axis) - cmin) / extent)
+		if b >= BVHBuckets {
+			b = BVHBuckets - 1
+		}
+		return b
+	}
+
+	for i := 0; i < len(objects); i++ {
+		var b = bucketIndex(objects[i])
+		buckets[b].Count++
+		buckets[b].Box.Expand(objects[i].Box)
+	}
+
+	// Evaluate the cost of splitting after each bucket
+	var area = node.Box.SurfaceArea()
+	var bestCost = -1.0
+	var bestSplit = 0
+
+	for i := 0; i < BVHBuckets - 1; i++ {
+		var left = NewEmptyAABB()
+		var right = NewEmptyAABB()
+		var leftCount = 0
+		var rightCount = 0
+
+		for j := 0; j <= i; j++ {
+			left.Expand(buckets[j].Box)
+			leftCount += buckets[j].Count
+		}
+		for j := i + 1; j < BVHBuckets; j++ {
+			right.Expand(buckets[j].Box)
+			rightCount += buckets[j].Count
+		}
+
+		var cost = BVHTraversalCost
+		if area > 0 {
+			cost += (float64(leftCount) * left.SurfaceArea() + float64(rightCount) * right.SurfaceArea()) / area
+		}
+
+		if bestCost < 0 || cost < bestCost {
+			bestCost = cost
+			bestSplit = i
+		}
+	}
+
+	// Create a leaf if splitting is more expensive than testing all objects
+	if len(objects) <= BVHMaxLeafSize && bestCost >= float64(len(objects)) {
+		return createBVHLeaf(node, objects)
+	}
+
+	// Partition the objects by the selected bucket
+	var mid = 0
+	for i := 0; i < len(objects); i++ {
+		if bucketIndex(objects[i]) <= bestSplit {
+			objects[i], objects[mid] = objects[mid], objects[i]
+			mid++
+		}
+	}
+
+	if mid == 0 || mid == len(objects) {
+		mid = len(objects) / 2
+	}
+
+	node.Left = buildBVH(objects[:mid])
+	node.Right = buildBVH(objects[mid:])
+
+	return node
+}
+
+// Store the objects in the node as a leaf.
+func createBVHLeaf(node *BVHNode, objects []*bvhObject) *BVHNode {
+	node.List = make([]Hitable, len(objects))
+	for i := 0; i < len(objects); i++ {
+		node.List[i] = objects[i].Object
+	}
+	return node
+}
+
+// Hit traverses the tree testing only the objects in nodes intersected by the ray.
+// The child closer to the ray origin is tested first to reduce the search distance faster.
+func (node *BVHNode) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	if !node.Box.Hit(ray, tmin, tmax) {
+		return false
+	}
+
+	var hitAnything = false
+
+	for i := 0; i < len(node.List); i++ {
+		if node.List[i].Hit(ray, tmin, tmax, hitRecord) {
+			hitAnything = true
+			tmax = hitRecord.T
+		}
+	}
+
+	var first = node.Left
+	var second = node.Right
+
+	if ray.Direction.Component(node.Axis) < 0 {
+		first = node.Right
+		second = node.Left
+	}
+
+	if first != nil && first.Hit(ray, tmin, tmax, hitRecord) {
+		hitAnything = true
+		tmax = hitRecord.T
+	}
+
+	if second != nil && second.Hit(ray, tmin, tmax, hitRecord) {
+		hitAnything = true
+	}
+
+	return hitAnything
+}
+
+func (node *BVHNode) BoundingBox(box *AABB) bool {
+	box.Copy(node.Box)
+	return true
+}
+
+func (o *BVHNode) Clone() Hitable {
+	var node = new(BVHNode)
+	node.Box = o.Box.Clone()
+	node.Axis = o.Axis
+
+	if o.Left != nil {
+		node.Left = o.Left.Clone().(*BVHNode)
+	}
+	if o.Right != nil {
+		node.Right = o.Right.Clone().(*BVHNode)
+	}
+
+	for i := 0; i < len(o.List); i++ {
+		node.List = append(node.List, o.List[i].Clone())
+	}
+
+	return node
+}
@@ -0,0 +1,165 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+)
+
+// Cone aligned with the Y axis, defined by the center of its base, the radius of the base and a height.
+// The apex of the cone is placed above the center of the base.
+type Cone struct {
+	// Center of the base of the cone.
+	Center *vmath.Vector3
+
+	// Radius of the base of the cone.
+	Radius float64
+
+	// Height of the apex of the cone, measured from the base along the Y axis.
+	Height float64
+
+	// If true the base of the cone is closed by a disk.
+	Capped bool
+
+	// Material used to render the cone.
+	Material material.Material
+}
+
+func NewCone(center *vmath.Vector3, radius float64, height float64, capped bool, material material.Material) *Cone {
+	var c = new(Cone)
+	c.Center = center
+	c.Radius = radius
+	c.Height = height
+	c.Capped = capped
+	c.Material = material
+	return c
+}
+
+// Intersect the line of the ray with the cone, returns the distances and outward normals of all intersections.
+func (c *Cone) intersections(ray *vmath.Ray) ([]float64, []*vmath.Vector3) {
+	var o = ray.Origin.Clone()
+	o.Sub(c.Center)
+	var d = ray.Direction
+
+	var distances []float64
+	var normals []*vmath.Vector3
+
+	// Side of the cone, x^2 + z^2 = (k * (h - y))^2 with k = r / h
+	var k = c.Radius / c.Height
+	var kSq = k * k
+	var h = c.Height - o.Y
+
+	var roots = vmath.SolveQuadratic(d.X * d.X + d.Z * d.Z - kSq * d.Y * d.Y, 2.0 * (o.X * d.X + o.Z * d.Z + kSq * h * d.Y), o.X * o.X + o.Z * o.Z - kSq * h * h)
+	for i := 0; i < len(roots); i++ {
+		var t = roots[i]
+		var y = o.Y + t * d.Y
+		if y < 0 || y > c.Height {
+			continue
+		}
+
+		var normal = vmath.NewVector3(o.X + t * d.X, kSq * (c.Height - y), o.Z + t * d.Z)
+		if normal.SquaredLength() > 0 {
+			normal.Normalize()
+		} else {
+			normal.Set(0.0, 1.0, 0.0)
+		}
+
+		distances = append(distances, t)
+		normals = append(normals, normal)
+	}
+
+	// Base of the cone
+	if c.Capped && d.Y != 0 {
+		var t = -o.Y / d.Y
+		var x = o.X + t * d.X
+		var z = o.Z + t * d.Z
+		if x * x + z * z <= c.Radius * c.Radius {
+			distances = append(distances, t)
+			normals = append(normals, vmath.NewVector3(0.0, -1.0, 0.0))
+		}
+	}
+
+	return distances, normals
+}
+
+// Intersect the ray with the cone, returns the distance and the outward normal at the closest intersection.
+func (c *Cone) Intersect(ray *vmath.Ray, tmin float64, tmax float64) (float64, *vmath.Vector3, bool) {
+	var distances, normals = c.intersections(ray)
+
+	var closest = tmax
+	var normal *vmath.Vector3
+
+	for i := 0; i < len(distances); i++ {
+		if distances[i] > tmin && distances[i] < closest {
+			closest = distances[i]
+			normal = normals[i]
+		}
+	}
+
+	return closest, normal, normal != nil
+}
+
+func (c *Cone) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var t, normal, ok = c.Intersect(ray, tmin, tmax)
+	if !ok {
+		return false
+	}
+
+	c.setHitRecord(ray, t, normal, hitRecord)
+	return true
+}
+
+// Fill the hit record with the surface of the cone at a distance along the ray.
+func (c *Cone) setHitRecord(ray *vmath.Ray, t float64, normal *vmath.Vector3, hitRecord *material.HitRecord) {
+	hitRecord.T = t
+	hitRecord.P = ray.PointAtParameter(t)
+	hitRecord.Normal = normal
+	hitRecord.FrontFace = vmath.Dot(ray.Direction, normal) < 0
+	hitRecord.Material = c.Material
+	hitRecord.U, hitRecord.V = c.UV(hitRecord.P, normal)
+}
+
+// Intersections are paired in intervals, open cones are treated as a hollow shell.
+func (c *Cone) Intervals(ray *vmath.Ray) []*Interval {
+	var distances, normals = c.intersections(ray)
+
+	var hits []*material.HitRecord
+	for i := 0; i < len(distances); i++ {
+		var hit = material.NewHitRecord()
+		c.setHitRecord(ray, distances[i], normals[i], hit)
+		hits = append(hits, hit)
+	}
+
+	return pairIntervals(hits)
+}
+
+// Texture coordinates of a point in the cone.
+// In the side U is the angle around the Y axis and V the height, in the base V is the distance to the center.
+func (c *Cone) UV(p *vmath.Vector3, normal *vmath.Vector3) (float64, float64) {
+	var local = p.Clone()
+	local.Sub(c.Center)
+
+	var u = (math.Atan2(-local.Z, local.X) + math.Pi) / (2.0 * math.Pi)
+
+	if normal.Y == -1.0 {
+		return u, clampUV(math.Sqrt(local.X * local.X + local.Z * local.Z) / c.Radius)
+	}
+
+	return u, clampUV(local.Y / c.Height)
+}
+
+func (c *Cone) BoundingBox(box *AABB) bool {
+	box.Min.Set(c.Center.X - c.Radius, c.Center.Y, c.Center.Z - c.Radius)
+	box.Max.Set(c.Center.X + c.Radius, c.Center.Y + c.Height, c.Center.Z + c.Radius)
+	return true
+}
+
+func (o *Cone) Clone() Hitable {
+	var c = new(Cone)
+	c.Center = o.Center.Clone()
+	c.Radius = o.Radius
+	c.Height = o.Height
+	c.Capped = o.Capped
+	c.Material = o.Material.Clone()
+	return c
+}
@@ -0,0 +1,100 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+)
+
+// Participating medium with constant density inside of a boundary solid, used to render fog and smoke.
+// Rays travelling inside of the medium scatter at a random distance that depends on the density.
+// The scattering direction is defined by the phase function material (isotropic or Henyey-Greenstein).
+type ConstantMedium struct {
+	// Solid that contains the medium.
+	Boundary Solid
+
+	// Probability of the ray scattering per unit of distance travelled inside of the medium.
+	Density float64
+
+	// Material used as phase function at the scattering points.
+	PhaseFunction material.Material
+}
+
+func NewConstantMedium(boundary Solid, density float64, phaseFunction material.Material) *ConstantMedium {
+	var m = new(ConstantMedium)
+	m.Boundary = boundary
+	m.Density = density
+	m.PhaseFunction = phaseFunction
+	return m
+}
+
+// The distance to the scattering event follows a exponential distribution.
+// The distance is consumed by the intervals of the ray inside of the boundary, if the ray leaves the boundary first there is no hit.
+func (m *ConstantMedium) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var length = ray.Direction.Length()
+	if length == 0 || m.Density <= 0 {
+		return false
+	}
+
+	var intervals = m.Boundary.Intervals(ray)
+	if len(intervals) == 0 {
+		return false
+	}
+
+	var distance = -math.Log(1.0 - rand.Float64()) / m.Density
+
+	for i := 0; i < len(intervals); i++ {
+		var enter = math.Max(intervals[i].Enter.T, tmin)
+		var exit = math.Min(intervals[i].Exit.T, tmax)
+		if enter >= exit {
+			continue
+		}
+
+		var inside = (exit - enter) * length
+		if distance < inside {
+			var t = enter + distance / length
+
+			hitRecord.T = t
+			hitRecord.P = ray.PointAtParameter(t)
+			hitRecord.Normal = ray.Direction.UnitVector()
+			hitRecord.Normal.MulScalar(-1.0)
+			hitRecord.FrontFace = true
+			hitRecord.Material = m.PhaseFunction
+			hitRecord.U, hitRecord.V = 0.0, 0.0
+			return true
+		}
+
+		distance -= inside
+	}
+
+	return false
+}
+
+// The transmittance decays exponentially with the distance travelled inside of the boundary.
+func (m *ConstantMedium) Transmittance(ray *vmath.Ray, tmin float64, tmax float64) float64 {
+	var intervals = m.Boundary.Intervals(ray)
+	var inside = 0.0
+
+	for i := 0; i < len(intervals); i++ {
+		var enter = math.Max(intervals[i].Enter.T, tmin)
+		var exit = math.Min(intervals[i].Exit.T, tmax)
+		if enter < exit {
+			inside += exit - enter
+		}
+	}
+
+	return math.Exp(-m.Density * inside * ray.Direction.Length())
+}
+
+func (m *ConstantMedium) BoundingBox(box *AABB) bool {
+	return m.Boundary.BoundingBox(box)
+}
+
+func (o *ConstantMedium) Clone() Hitable {
+	var m = new(ConstantMedium)
+	m.Boundary = o.Boundary.Clone().(Solid)
+	m.Density = o.Density
+	m.PhaseFunction = o.PhaseFunction.Clone()
+	return m
+}
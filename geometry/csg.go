@@ -0,0 +1,190 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"sort"
+)
+
+// Boolean operation used to combine solids.
+type CSGOperation int
+
+const (
+	// Points inside of any of the solids.
+	CSGUnion CSGOperation = iota
+
+	// Points inside of both solids.
+	CSGIntersection
+
+	// Points inside of the left solid and outside of the right solid.
+	CSGDifference
+)
+
+// Constructive solid geometry node, combines two solids using a boolean operation.
+// The surfaces keep the material of the solid where they came from, surfaces of the right solid in a difference are inverted.
+type CSG struct {
+	Operation CSGOperation
+
+	Left Solid
+	Right Solid
+
+	// Bounding box of the result of the operation, nil if the solids are not bounded.
+	// Calculated when the node is created, the solids should not be changed after.
+	Box *AABB
+}
+
+// Create new CSG node combining two solids.
+func NewCSG(operation CSGOperation, left Solid, right Solid) *CSG {
+	var c = new(CSG)
+	c.Operation = operation
+	c.Left = left
+	c.Right = right
+	c.updateBox()
+	return c
+}
+
+// Check if a point is inside of the result of the operation.
+func (c *CSG) inside(left bool, right bool) bool {
+	switch c.Operation {
+	case CSGIntersection:
+		return left && right
+	case CSGDifference:
+		return left && !right
+	}
+	return left || right
+}
+
+// Surface where the ray crosses the boundary of one of the solids.
+type csgEvent struct {
+	Surface *material.HitRecord
+
+	// Indicates if the surface belongs to the left solid.
+	Left bool
+
+	// Indicates if the ray enters the solid at the surface.
+	Enter bool
+}
+
+// The intervals of both solids are combined by walking along the ray and tracking if the ray is inside of each solid.
+// Surfaces where the ray enters or exits the result of the operation are the boundaries of the new intervals.
+func (c *CSG) Intervals(ray *vmath.Ray) []*Interval {
+	var events []*csgEvent
+
+	var operands = []Solid{c.Left, c.Right}
+	for i := 0; i < len(operands); i++ {
+		var intervals = operands[i].Intervals(ray)
+		for j := 0; j < len(intervals); j++ {
+			events = append(events, &csgEvent{intervals[j].Enter, i == 0, true}, &csgEvent{intervals[j].Exit, i == 0, false})
+		}
+	}
+
+	sort.SliceStable(events, func(i int, j int) bool {
+		return events[i].Surface.T < events[j].Surface.T
+	})
+
+	var result []*Interval
+	var enter *material.HitRecord
+	var left, right = 0, 0
+
+	for i := 0; i < len(events); i++ {
+		var event = events[i]
+		var before = c.inside(left > 0, right > 0)
+
+		var count = 1
+		if !event.Enter {
+			count = -1
+		}
+		if event.Left {
+			left += count
+		} else {
+			right += count
+		}
+
+		var after = c.inside(left > 0, right > 0)
+		if before == after {
+			continue
+		}
+
+		// Surfaces crossed in the opposite direction of the result (e.g. the right solid of a difference) are inverted
+		var surface = event.Surface
+		if event.Enter != after {
+			surface = new(material.HitRecord)
+			*surface = *event.Surface
+			surface.Normal = event.Surface.Normal.Clone()
+			surface.Normal.MulScalar(-1.0)
+		}
+
+		if after {
+			enter = surface
+		} else {
+			result = append(result, NewInterval(enter, surface))
+		}
+	}
+
+	return result
+}
+
+func (c *CSG) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	if c.Box != nil && !c.Box.Hit(ray, tmin, tmax) {
+		return false
+	}
+
+	return hitIntervals(c.Intervals(ray), ray, tmin, tmax, hitRecord)
+}
+
+func (c *CSG) BoundingBox(box *AABB) bool {
+	if c.Box == nil {
+		return false
+	}
+
+	box.Copy(c.Box)
+	return true
+}
+
+// Calculate the bounding box of the node from the boxes of the solids.
+func (c *CSG) updateBox() {
+	c.Box = NewEmptyAABB()
+	if !c.combineBoxes(c.Box) {
+		c.Box = nil
+	}
+}
+
+// The union contains both boxes, the intersection is inside of both boxes and the difference is inside of the left box.
+func (c *CSG) combineBoxes(box *AABB) bool {
+	var left = NewEmptyAABB()
+	if !c.Left.BoundingBox(left) {
+		return false
+	}
+
+	if c.Operation == CSGDifference {
+		box.Copy(left)
+		return true
+	}
+
+	var right = NewEmptyAABB()
+	if !c.Right.BoundingBox(right) {
+		return false
+	}
+
+	if c.Operation == CSGUnion {
+		box.Copy(left)
+		box.Expand(right)
+		return true
+	}
+
+	left.Min.Max(right.Min)
+	left.Max.Min(right.Max)
+	box.Copy(left)
+	return true
+}
+
+func (c *CSG) Clone() Hitable {
+	var n = new(CSG)
+	n.Operation = c.Operation
+	n.Left = c.Left.Clone().(Solid)
+	n.Right = c.Right.Clone().(Solid)
+	if c.Box != nil {
+		n.Box = c.Box.Clone()
+	}
+	return n
+}
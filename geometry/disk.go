@@ -0,0 +1,144 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+)
+
+// Flat circular disk defined by a center, a normal and a radius.
+type Disk struct {
+	Center *vmath.Vector3
+
+	// Normal direction of the disk (normalized).
+	Normal *vmath.Vector3
+
+	Radius float64
+
+	// Material used to render the disk.
+	Material material.Material
+}
+
+func NewDisk(center *vmath.Vector3, normal *vmath.Vector3, radius float64, material material.Material) *Disk {
+	var d = new(Disk)
+	d.Center = center
+	d.Normal = normal.UnitVector()
+	d.Radius = radius
+	d.Material = material
+	return d
+}
+
+// Intersect the ray with the disk, returns the distance to the intersection.
+func (d *Disk) Intersect(ray *vmath.Ray, tmin float64, tmax float64) (float64, bool) {
+	var denominator = vmath.Dot(d.Normal, ray.Direction)
+	if math.Abs(denominator) < 1e-12 {
+		return 0, false
+	}
+
+	var difference = d.Center.Clone()
+	difference.Sub(ray.Origin)
+
+	var t = vmath.Dot(difference, d.Normal) / denominator
+	if t >= tmax || t <= tmin {
+		return 0, false
+	}
+
+	var p = ray.PointAtParameter(t)
+	p.Sub(d.Center)
+	if p.SquaredLength() > d.Radius * d.Radius {
+		return 0, false
+	}
+
+	return t, true
+}
+
+func (d *Disk) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var t, ok = d.Intersect(ray, tmin, tmax)
+	if !ok {
+		return false
+	}
+
+	hitRecord.T = t
+	hitRecord.P = ray.PointAtParameter(t)
+	hitRecord.Normal = d.Normal.Clone()
+	hitRecord.FrontFace = vmath.Dot(ray.Direction, d.Normal) < 0
+	hitRecord.Material = d.Material
+	hitRecord.U, hitRecord.V = d.UV(hitRecord.P)
+	return true
+}
+
+// Texture coordinates in polar form, U is the angle around the center and V the distance to the center relative to the radius.
+func (d *Disk) UV(p *vmath.Vector3) (float64, float64) {
+	var basis = vmath.NewONB(d.Normal)
+
+	var local = p.Clone()
+	local.Sub(d.Center)
+
+	var x = vmath.Dot(local, basis.U)
+	var y = vmath.Dot(local, basis.V)
+
+	return (math.Atan2(y, x) + math.Pi) / (2.0 * math.Pi), math.Min(math.Sqrt(x * x + y * y) / d.Radius, 1.0)
+}
+
+// The extent of the disk along each axis depends on the angle between the axis and the normal.
+func (d *Disk) BoundingBox(box *AABB) bool {
+	var extent = vmath.NewVector3(
+		d.Radius * math.Sqrt(math.Max(0.0, 1.0 - d.Normal.X * d.Normal.X)) + TriangleBoxPadding,
+		d.Radius * math.Sqrt(math.Max(0.0, 1.0 - d.Normal.Y * d.Normal.Y)) + TriangleBoxPadding,
+		d.Radius * math.Sqrt(math.Max(0.0, 1.0 - d.Normal.Z * d.Normal.Z)) + TriangleBoxPadding)
+
+	box.Min.Copy(d.Center)
+	box.Min.Sub(extent)
+	box.Max.Copy(d.Center)
+	box.Max.Add(extent)
+
+	return true
+}
+
+// Area of the disk.
+func (d *Disk) Area() float64 {
+	return math.Pi * d.Radius * d.Radius
+}
+
+func (d *Disk) Emissive() bool {
+	return material.IsEmissive(d.Material)
+}
+
+// Points are sampled uniformly over the area of the disk.
+func (d *Disk) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var r = d.Radius * math.Sqrt(rand.Float64())
+	var phi = 2.0 * math.Pi * rand.Float64()
+
+	var point = vmath.NewONB(d.Normal).Local(r * math.Cos(phi), r * math.Sin(phi), 0.0)
+	point.Add(d.Center)
+	point.Sub(origin)
+	return point
+}
+
+// The area density is converted to solid angle using the distance and the angle of the surface.
+func (d *Disk) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var t, ok = d.Intersect(vmath.NewRay(origin, direction), 0.0, math.MaxFloat64)
+	if !ok {
+		return 0.0
+	}
+
+	var length = direction.Length()
+	var distanceSq = t * t * length * length
+	var cosine = math.Abs(vmath.Dot(direction, d.Normal)) / length
+
+	if cosine <= 0 {
+		return 0.0
+	}
+
+	return distanceSq / (cosine * d.Area())
+}
+
+func (o *Disk) Clone() Hitable {
+	var d = new(Disk)
+	d.Center = o.Center.Clone()
+	d.Normal = o.Normal.Clone()
+	d.Radius = o.Radius
+	d.Material = o.Material.Clone()
+	return d
+}
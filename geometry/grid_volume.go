@@ -0,0 +1,314 @@
+package geometry
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gotracer/material"
+	"gotracer/texture"
+	"gotracer/vmath"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// Magic number at the start of grid volume files.
+const GridFileMagic = "GRID"
+
+// Size of the header of grid volume files, the magic number followed by three uint32 sizes.
+const GridHeaderSize = 16
+
+// Maximum number of voxels along each axis and in total of grid files, larger grids are rejected before allocating memory.
+const GridMaxSize = 4096
+const GridMaxVoxels = 512 * 512 * 512
+
+// Number of voxels read at a time from grid files, truncated files are detected before the whole grid is allocated.
+const gridReadChunk = 65536
+
+// Heterogeneous participating medium, the density is stored in a grid of voxels placed inside of a box.
+// Used to render clouds and smoke simulations, the density between voxel centers is interpolated.
+// Scattering is sampled with delta tracking and the transmittance is estimated with ratio tracking.
+type GridVolume struct {
+	// Box where the grid is placed, the voxels are distributed uniformly inside of it.
+	Box *AABB
+
+	// Number of voxels along each axis.
+	Width int
+	Height int
+	Depth int
+
+	// Density of each voxel, stored with X varying fastest, then Y and then Z.
+	Data []float64
+
+	// Scale applied to the density values of the grid.
+	Density float64
+
+	// Material used as phase function at the scattering points.
+	PhaseFunction material.Material
+
+	// Maximum density of the volume (majorant), used to sample the tentative collisions.
+	majorant float64
+}
+
+// Create new grid volume from the density of the voxels.
+func NewGridVolume(box *AABB, width int, height int, depth int, data []float64, density float64, phaseFunction material.Material) *GridVolume {
+	var g = new(GridVolume)
+	g.Box = box
+	g.Width = width
+	g.Height = height
+	g.Depth = depth
+	g.Data = data
+	g.Density = density
+	g.PhaseFunction = phaseFunction
+	g.UpdateMajorant()
+	return g
+}
+
+// Create new grid volume with a cloud like density generated from noise.
+// The turbulence of the noise is faded towards the border of the box, the scale is the frequency of the noise.
+func NewNoiseGridVolume(box *AABB, resolution int, perlin *texture.Perlin, scale float64, density float64, phaseFunction material.Material) *GridVolume {
+	var data = make([]float64, resolution * resolution * resolution)
+
+	for z := 0; z < resolution; z++ {
+		for y := 0; y < resolution; y++ {
+			for x := 0; x < resolution; x++ {
+				var p = vmath.NewVector3((float64(x) + 0.5) / float64(resolution), (float64(y) + 0.5) / float64(resolution), (float64(z) + 0.5) / float64(resolution))
+
+				var center = vmath.NewVector3(p.X * 2.0 - 1.0, p.Y * 2.0 - 1.0, p.Z * 2.0 - 1.0)
+				var falloff = math.Max(0.0, 1.0 - center.Length())
+
+				p.MulScalar(scale)
+				data[x + resolution * (y + resolution * z)] = math.Max(0.0, 2.0 * perlin.Turbulence(p, 7) * falloff - 0.1)
+			}
+		}
+	}
+
+	return NewGridVolume(box, resolution, resolution, resolution, data, density, phaseFunction)
+}
+
+// Load a grid volume from a file, the density of the grid is placed inside of the box.
+func LoadGridVolume(fname string, box *AABB, density float64, phaseFunction material.Material) (*GridVolume, error) {
+	var file, err = os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var width, height, depth int
+	var data []float64
+	width, height, depth, data, err = ReadGrid(bufio.NewReader(file))
+	if err == nil {
+		err = checkGridFileSize(file, width * height * depth)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", fname, err.Error())
+	}
+
+	return NewGridVolume(box, width, height, depth, data, density, phaseFunction), nil
+}
+
+// Read a density grid, the format is the "GRID" magic followed by the width, height and depth as little endian uint32.
+// After the header the density of the voxels is stored as little endian float32 values, with X varying fastest, then Y and then Z.
+func ReadGrid(reader io.Reader) (int, int, int, []float64, error) {
+	var magic = make([]byte, len(GridFileMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != GridFileMagic {
+		return 0, 0, 0, nil, errors.New("not a grid volume file")
+	}
+
+	var size [3]uint32
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return 0, 0, 0, nil, errors.New("truncated grid header")
+	}
+	if size[0] == 0 || size[1] == 0 || size[2] == 0 {
+		return 0, 0, 0, nil, errors.New("grid size cannot be zero")
+	}
+	if size[0] > GridMaxSize || size[1] > GridMaxSize || size[2] > GridMaxSize {
+		return 0, 0, 0, nil, fmt.Errorf("grid size %dx%dx%d is larger than %d voxels along a axis", size[0], size[1], size[2], GridMaxSize)
+	}
+
+	// Each size is limited, the product fits in 64 bits
+	var count = uint64(size[0]) * uint64(size[1]) * uint64(size[2])
+	if count > GridMaxVoxels {
+		return 0, 0, 0, nil, fmt.Errorf("grid has %d voxels, the maximum is %d", count, GridMaxVoxels)
+	}
+
+	// Data is read in chunks, memory grows with the data found in the file instead of the size in the header
+	var data = make([]float64, 0, int(math.Min(float64(count), gridReadChunk)))
+	var values = make([]float32, gridReadChunk)
+
+	for uint64(len(data)) < count {
+		var chunk = values[:int(math.Min(float64(count - uint64(len(data))), gridReadChunk))]
+		if err := binary.Read(reader, binary.LittleEndian, chunk); err != nil {
+			return 0, 0, 0, nil, errors.New("truncated grid data")
+		}
+
+		for i := 0; i < len(chunk); i++ {
+			if chunk[i] < 0 || math.IsNaN(float64(chunk[i])) {
+				return 0, 0, 0, nil, fmt.Errorf("invalid density %v at voxel %d", chunk[i], len(data))
+			}
+			data = append(data, float64(chunk[i]))
+		}
+	}
+
+	return int(size[0]), int(size[1]), int(size[2]), data, nil
+}
+
+// Check that a grid file has exactly the size of the header and the voxels of the grid.
+func checkGridFileSize(file *os.File, count int) error {
+	var info, err = file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var expected = int64(GridHeaderSize) + 4 * int64(count)
+	if info.Size() != expected {
+		return fmt.Errorf("grid file has %d bytes, expected %d for %d voxels", info.Size(), expected, count)
+	}
+
+	return nil
+}
+
+// Calculate the maximum density of the volume, has to be called after the grid data or the density is changed.
+func (g *GridVolume) UpdateMajorant() {
+	var max = 0.0
+	for i := 0; i < len(g.Data); i++ {
+		max = math.Max(max, g.Data[i])
+	}
+
+	g.majorant = max * g.Density
+}
+
+// Density of a voxel, the coordinates are clamped to the grid.
+func (g *GridVolume) voxel(x int, y int, z int) float64 {
+	return g.Data[clampIndex(x, g.Width) + g.Width * (clampIndex(y, g.Height) + g.Height * clampIndex(z, g.Depth))]
+}
+
+// Clamp a index to the range [0, size - 1].
+func clampIndex(i int, size int) int {
+	if i < 0 {
+		return 0
+	} else if i >= size {
+		return size - 1
+	}
+	return i
+}
+
+// Linear interpolation between two values.
+func lerp(a float64, b float64, t float64) float64 {
+	return a + (b - a) * t
+}
+
+// Density of the volume at a point, interpolated from the eight closest voxel centers.
+// Points outside of the box have no density.
+func (g *GridVolume) DensityAt(p *vmath.Vector3) float64 {
+	var size = g.Box.Max.Clone()
+	size.Sub(g.Box.Min)
+
+	var x = (p.X - g.Box.Min.X) / size.X
+	var y = (p.Y - g.Box.Min.Y) / size.Y
+	var z = (p.Z - g.Box.Min.Z) / size.Z
+
+	if x < 0 || x > 1 || y < 0 || y > 1 || z < 0 || z > 1 {
+		return 0.0
+	}
+
+	x = x * float64(g.Width) - 0.5
+	y = y * float64(g.Height) - 0.5
+	z = z * float64(g.Depth) - 0.5
+
+	var x0, y0, z0 = math.Floor(x), math.Floor(y), math.Floor(z)
+	var fx, fy, fz = x - x0, y - y0, z - z0
+	var i, j, k = int(x0), int(y0), int(z0)
+
+	var c00 = lerp(g.voxel(i, j, k), g.voxel(i + 1, j, k), fx)
+	var c10 = lerp(g.voxel(i, j + 1, k), g.voxel(i + 1, j + 1, k), fx)
+	var c01 = lerp(g.voxel(i, j, k + 1), g.voxel(i + 1, j, k + 1), fx)
+	var c11 = lerp(g.voxel(i, j + 1, k + 1), g.voxel(i + 1, j + 1, k + 1), fx)
+
+	return lerp(lerp(c00, c10, fy), lerp(c01, c11, fy), fz) * g.Density
+}
+
+// Distance along the ray to the next tentative collision, sampled using the majorant density.
+func (g *GridVolume) step(length float64) float64 {
+	return -math.Log(1.0 - rand.Float64()) / (g.majorant * length)
+}
+
+// Delta tracking, tentative collisions are sampled with the majorant density.
+// Each collision is accepted as a real scattering event with probability proportional to the density at the point.
+func (g *GridVolume) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var length = ray.Direction.Length()
+	if length == 0 || g.majorant <= 0 {
+		return false
+	}
+
+	var t, exit, ok = g.Box.Intersect(ray, tmin, tmax)
+	if !ok {
+		return false
+	}
+
+	for {
+		t += g.step(length)
+		if t >= exit {
+			return false
+		}
+
+		var p = ray.PointAtParameter(t)
+		if rand.Float64() * g.majorant < g.DensityAt(p) {
+			hitRecord.T = t
+			hitRecord.P = p
+			hitRecord.Normal = ray.Direction.UnitVector()
+			hitRecord.Normal.MulScalar(-1.0)
+			hitRecord.FrontFace = true
+			hitRecord.Material = g.PhaseFunction
+			hitRecord.U, hitRecord.V = 0.0, 0.0
+			return true
+		}
+	}
+}
+
+// Ratio tracking, the transmittance is multiplied by the probability of each tentative collision being a null collision.
+func (g *GridVolume) Transmittance(ray *vmath.Ray, tmin float64, tmax float64) float64 {
+	var length = ray.Direction.Length()
+	if length == 0 || g.majorant <= 0 {
+		return 1.0
+	}
+
+	var t, exit, ok = g.Box.Intersect(ray, tmin, tmax)
+	if !ok {
+		return 1.0
+	}
+
+	var transmittance = 1.0
+	for {
+		t += g.step(length)
+		if t >= exit {
+			return transmittance
+		}
+
+		transmittance *= 1.0 - g.DensityAt(ray.PointAtParameter(t)) / g.majorant
+		if transmittance <= 0 {
+			return 0.0
+		}
+	}
+}
+
+func (g *GridVolume) BoundingBox(box *AABB) bool {
+	box.Copy(g.Box)
+	return true
+}
+
+// The voxel data is shared with the new volume.
+func (o *GridVolume) Clone() Hitable {
+	var g = new(GridVolume)
+	g.Box = o.Box.Clone()
+	g.Width = o.Width
+	g.Height = o.Height
+	g.Depth = o.Depth
+	g.Data = o.Data
+	g.Density = o.Density
+	g.PhaseFunction = o.PhaseFunction.Clone()
+	g.majorant = o.majorant
+	return g
+}
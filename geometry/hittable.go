@@ -11,6 +11,10 @@ type Hitable interface {
 	// If true the result is stored on the hitrecord object provided.
 	Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool
 
+	// Calculate the bounding box of the object, the result is stored in the box provided.
+	// Returns false if the object is infinite and has no bounding box.
+	BoundingBox(box *AABB) bool
+
 	// Clone object create a new object with the same properties.
 	Clone() Hitable
 }
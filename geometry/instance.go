@@ -0,0 +1,145 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+)
+
+// Instance places a object in the scene using a transformation matrix.
+// Rays are transformed into the object space, the object itself is not modified and can be shared by multiple instances.
+type Instance struct {
+	// Object being instanced, defined in object space.
+	Object Hitable
+
+	// Transform from object space to world space.
+	Transform *vmath.Matrix4
+
+	// Transform from world space to object space.
+	Inverse *vmath.Matrix4
+}
+
+// Create new instance of a object, the transform matrix has to be invertible.
+func NewInstance(object Hitable, transform *vmath.Matrix4) *Instance {
+	var inverse, ok = transform.Inverse()
+	if !ok {
+		panic("geometry: instance transform cannot be inverted")
+	}
+
+	var i = new(Instance)
+	i.Object = object
+	i.Transform = transform
+	i.Inverse = inverse
+	return i
+}
+
+// Transform a ray from world space to object space.
+// The direction is not normalized, distances along the ray are the same in both spaces.
+func (i *Instance) objectRay(ray *vmath.Ray) *vmath.Ray {
+	return vmath.NewRayTime(i.Inverse.TransformPoint(ray.Origin), i.Inverse.TransformVector(ray.Direction), ray.Time)
+}
+
+func (i *Instance) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	if !i.Object.Hit(i.objectRay(ray), tmin, tmax, hitRecord) {
+		return false
+	}
+
+	hitRecord.P = i.Transform.TransformPoint(hitRecord.P)
+	hitRecord.Normal = i.Inverse.TransformNormal(hitRecord.Normal)
+	return true
+}
+
+// Intervals are calculated in object space, distances are the same in both spaces and only the surfaces are transformed.
+// Returns no intervals if the object is not a solid.
+func (i *Instance) Intervals(ray *vmath.Ray) []*Interval {
+	var solid, ok = i.Object.(Solid)
+	if !ok {
+		return nil
+	}
+
+	var intervals = solid.Intervals(i.objectRay(ray))
+	for j := 0; j < len(intervals); j++ {
+		var surfaces = []*material.HitRecord{intervals[j].Enter, intervals[j].Exit}
+		for k := 0; k < len(surfaces); k++ {
+			surfaces[k].P = i.Transform.TransformPoint(surfaces[k].P)
+			surfaces[k].Normal = i.Inverse.TransformNormal(surfaces[k].Normal)
+		}
+	}
+
+	return intervals
+}
+
+// The transmittance is calculated in object space, returns one if the object is not a volume.
+func (i *Instance) Transmittance(ray *vmath.Ray, tmin float64, tmax float64) float64 {
+	var volume, ok = i.Object.(Volume)
+	if !ok {
+		return 1.0
+	}
+
+	return volume.Transmittance(i.objectRay(ray), tmin, tmax)
+}
+
+// The bounding box contains the eight corners of the object box transformed to world space.
+func (i *Instance) BoundingBox(box *AABB) bool {
+	var local = NewEmptyAABB()
+	if !i.Object.BoundingBox(local) {
+		return false
+	}
+
+	box.Copy(NewEmptyAABB())
+
+	for c := 0; c < 8; c++ {
+		var corner = local.Min.Clone()
+		if c & 1 != 0 {
+			corner.X = local.Max.X
+		}
+		if c & 2 != 0 {
+			corner.Y = local.Max.Y
+		}
+		if c & 4 != 0 {
+			corner.Z = local.Max.Z
+		}
+		box.ExpandPoint(i.Transform.TransformPoint(corner))
+	}
+
+	return true
+}
+
+// The instance is emissive if the object is a emissive light.
+func (i *Instance) Emissive() bool {
+	var light, ok = i.Object.(Light)
+	return ok && light.Emissive()
+}
+
+// The direction is sampled in object space and transformed back to world space.
+func (i *Instance) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var direction = i.Object.(Light).SampleDirection(i.Inverse.TransformPoint(origin), time)
+	return i.Transform.TransformVector(direction)
+}
+
+// The density of the object space direction is converted to world space solid angle using the jacobian of the transform.
+// For rigid transforms and uniform scales the density is the same in both spaces.
+func (i *Instance) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var light, ok = i.Object.(Light)
+	if !ok {
+		return 0.0
+	}
+
+	var unit = direction.UnitVector()
+	var local = i.Inverse.TransformVector(unit)
+	var length = local.Length()
+	if length == 0 {
+		return 0.0
+	}
+
+	var pdf = light.PDF(i.Inverse.TransformPoint(origin), local, time)
+	return pdf * math.Abs(i.Inverse.Determinant()) / (length * length * length)
+}
+
+func (i *Instance) Clone() Hitable {
+	var c = new(Instance)
+	c.Object = i.Object.Clone()
+	c.Transform = i.Transform.Clone()
+	c.Inverse = i.Inverse.Clone()
+	return c
+}
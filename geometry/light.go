@@ -0,0 +1,22 @@
+package geometry
+
+import (
+	"gotracer/vmath"
+)
+
+// Light interface indicates a object that can be sampled directly, used for direct light sampling.
+// Light sources are sampled by casting rays from the surfaces towards them.
+type Light interface {
+	Hitable
+
+	// Indicates if the object emits light, only emissive objects are used as light sources.
+	Emissive() bool
+
+	// Sample a random direction from the origin towards the surface of the object.
+	// The time of the ray is used by moving objects, static objects ignore it.
+	SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3
+
+	// Probability density (relative to solid angle) of sampling a direction from the origin.
+	// Returns zero if the direction does not intersect the object.
+	PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64
+}
@@ -0,0 +1,310 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Mesh is a list of triangles sharing vertex data.
+// Vertices, normals and texture coordinates are stored once and referenced by the faces using index buffers.
+// The faces are stored in a BVH built when the mesh is created.
+type Mesh struct {
+	// Vertex positions.
+	Vertices []vmath.Vector3
+
+	// Vertex normals, used to interpolate the shading normal.
+	Normals []vmath.Vector3
+
+	// Vertex texture coordinates.
+	UVs []vmath.Vector2
+
+	// Vertex index buffer, three indices per face.
+	Indices []int
+
+	// Normal index buffer, three indices per face.
+	// If nil or if any of the face indices is negative the face is flat shaded.
+	NormalIndices []int
+
+	// Texture coordinates index buffer, three indices per face.
+	// If nil or if any of the face indices is negative the barycentric coordinates are used.
+	UVIndices []int
+
+	// Materials used by the faces.
+	Materials []material.Material
+
+	// Index of the material of each face, if nil all faces use the first material.
+	FaceMaterials []int
+
+	// Faces ignored by the ray intersection, the mesh is double sided by default.
+	Cull CullMode
+
+	// Acceleration structure of the faces.
+	BVH *BVHNode
+
+	// Emissive faces and their cumulative area, used for light sampling.
+	lights []int
+	lightsArea []float64
+}
+
+// Face of a mesh, stored in the mesh BVH.
+type MeshTriangle struct {
+	Mesh *Mesh
+
+	// Index of the face in the mesh.
+	Face int
+}
+
+// Create new mesh from vertices and a index buffer, all faces use the same material.
+func NewMesh(vertices []vmath.Vector3, indices []int, material material.Material) *Mesh {
+	var m = new(Mesh)
+	m.Vertices = vertices
+	m.Indices = indices
+	m.Materials = append(m.Materials, material)
+	m.Build()
+	return m
+}
+
+// Build the BVH and the list of emissive faces.
+// Has to be called after changing the vertices, index buffers or materials of the mesh.
+func (m *Mesh) Build() {
+	var faces = make([]Hitable, m.FaceCount())
+
+	for i := 0; i < len(faces); i++ {
+		var face = new(MeshTriangle)
+		face.Mesh = m
+		face.Face = i
+		faces[i] = face
+	}
+
+	m.BVH = NewBVH(faces)
+
+	m.lights = nil
+	m.lightsArea = nil
+
+	var total = 0.0
+	for i := 0; i < len(faces); i++ {
+		if material.IsEmissive(m.FaceMaterial(i)) {
+			total += m.FaceArea(i)
+			m.lights = append(m.lights, i)
+			m.lightsArea = append(m.lightsArea, total)
+		}
+	}
+}
+
+// Number of faces in the mesh.
+func (m *Mesh) FaceCount() int {
+	return len(m.Indices) / 3
+}
+
+// Get the vertices of a face.
+func (m *Mesh) FaceVertices(face int) (*vmath.Vector3, *vmath.Vector3, *vmath.Vector3) {
+	return &m.Vertices[m.Indices[face * 3]], &m.Vertices[m.Indices[face * 3 + 1]], &m.Vertices[m.Indices[face * 3 + 2]]
+}
+
+// Get the material of a face.
+func (m *Mesh) FaceMaterial(face int) material.Material {
+	if m.FaceMaterials == nil {
+		return m.Materials[0]
+	}
+	return m.Materials[m.FaceMaterials[face]]
+}
+
+// Normal of the plane of a face, in the same direction as the Triangle normal.
+func (m *Mesh) FaceNormal(face int) *vmath.Vector3 {
+	var a, b, c = m.FaceVertices(face)
+
+	var bc = c.Clone()
+	bc.Sub(b)
+
+	var ba = a.Clone()
+	ba.Sub(b)
+
+	var normal = vmath.Cross(bc, ba)
+	if normal.SquaredLength() > 0 {
+		normal.Normalize()
+	}
+	return normal
+}
+
+// Area of a face.
+func (m *Mesh) FaceArea(face int) float64 {
+	var a, b, c = m.FaceVertices(face)
+
+	var ab = b.Clone()
+	ab.Sub(a)
+
+	var ac = c.Clone()
+	ac.Sub(a)
+
+	return vmath.Cross(ab, ac).Length() / 2.0
+}
+
+// Calculate the shading normal of a face from the barycentric coordinates of a point (relative to B and C).
+// The vertex normals are interpolated if available, otherwise the face normal is used.
+func (m *Mesh) ShadingNormal(face int, u float64, v float64) *vmath.Vector3 {
+	if m.NormalIndices == nil {
+		return m.FaceNormal(face)
+	}
+
+	var ia, ib, ic = m.NormalIndices[face * 3], m.NormalIndices[face * 3 + 1], m.NormalIndices[face * 3 + 2]
+	if ia < 0 || ib < 0 || ic < 0 {
+		return m.FaceNormal(face)
+	}
+
+	var normal = m.Normals[ia].Clone()
+	normal.MulScalar(1.0 - u - v)
+
+	var b = m.Normals[ib].Clone()
+	b.MulScalar(u)
+
+	var c = m.Normals[ic].Clone()
+	c.MulScalar(v)
+
+	normal.Add(b)
+	normal.Add(c)
+
+	if normal.SquaredLength() == 0 {
+		return m.FaceNormal(face)
+	}
+
+	normal.Normalize()
+	return normal
+}
+
+// Calculate the texture coordinates of a face from the barycentric coordinates of a point (relative to B and C).
+func (m *Mesh) UV(face int, u float64, v float64) (float64, float64) {
+	if m.UVIndices == nil {
+		return u, v
+	}
+
+	var ia, ib, ic = m.UVIndices[face * 3], m.UVIndices[face * 3 + 1], m.UVIndices[face * 3 + 2]
+	if ia < 0 || ib < 0 || ic < 0 {
+		return u, v
+	}
+
+	var w = 1.0 - u - v
+	var a, b, c = &m.UVs[ia], &m.UVs[ib], &m.UVs[ic]
+	return w * a.X + u * b.X + v * c.X, w * a.Y + u * b.Y + v * c.Y
+}
+
+func (m *Mesh) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	return m.BVH.Hit(ray, tmin, tmax, hitRecord)
+}
+
+func (m *Mesh) BoundingBox(box *AABB) bool {
+	if m.FaceCount() == 0 {
+		return false
+	}
+
+	box.Copy(m.BVH.Box)
+	return true
+}
+
+// The mesh is emissive if any of its faces uses a emissive material.
+func (m *Mesh) Emissive() bool {
+	return len(m.lights) > 0
+}
+
+// Emissive faces are selected proportionally to their area, and points are sampled uniformly over the face.
+func (m *Mesh) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var total = m.lightsArea[len(m.lightsArea) - 1]
+	var i = sort.SearchFloat64s(m.lightsArea, rand.Float64() * total)
+	if i >= len(m.lights) {
+		i = len(m.lights) - 1
+	}
+
+	var a, b, c = m.FaceVertices(m.lights[i])
+	var triangle = new(Triangle)
+	triangle.A, triangle.B, triangle.C = a, b, c
+	return triangle.SampleDirection(origin, time)
+}
+
+// The density is the sum of the density of all emissive faces intersected by the direction.
+// Each point of the emissive surface has the same area density, one over the total emissive area.
+func (m *Mesh) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	if len(m.lights) == 0 {
+		return 0.0
+	}
+
+	var ray = vmath.NewRay(origin, direction)
+	var total = m.lightsArea[len(m.lightsArea) - 1]
+	return m.lightPDF(m.BVH, ray, total)
+}
+
+// Recursively sum the density of the emissive faces in a BVH node intersected by the ray.
+func (m *Mesh) lightPDF(node *BVHNode, ray *vmath.Ray, total float64) float64 {
+	if node == nil || !node.Box.Hit(ray, 0.0, math.MaxFloat64) {
+		return 0.0
+	}
+
+	var pdf = 0.0
+
+	for i := 0; i < len(node.List); i++ {
+		var face = node.List[i].(*MeshTriangle).Face
+		if !material.IsEmissive(m.FaceMaterial(face)) {
+			continue
+		}
+
+		var a, b, c = m.FaceVertices(face)
+		var t, _, _, ok = IntersectTriangle(a, b, c, ray, 0.0, math.MaxFloat64, CullNone)
+		if !ok {
+			continue
+		}
+
+		var length = ray.Direction.Length()
+		var distanceSq = t * t * length * length
+		var cosine = math.Abs(vmath.Dot(ray.Direction, m.FaceNormal(face))) / length
+		if cosine > 0 {
+			pdf += distanceSq / (cosine * total)
+		}
+	}
+
+	return pdf + m.lightPDF(node.Left, ray, total) + m.lightPDF(node.Right, ray, total)
+}
+
+// The mesh data is read only while rendering, clones share the vertex data and the BVH.
+func (m *Mesh) Clone() Hitable {
+	var c = new(Mesh)
+	*c = *m
+	return c
+}
+
+func (face *MeshTriangle) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var a, b, c = face.Mesh.FaceVertices(face.Face)
+	var t, u, v, ok = IntersectTriangle(a, b, c, ray, tmin, tmax, face.Mesh.Cull)
+
+	if ok {
+		hitRecord.T = t
+		hitRecord.P = ray.PointAtParameter(t)
+		hitRecord.Normal = face.Mesh.ShadingNormal(face.Face, u, v)
+		hitRecord.FrontFace = vmath.Dot(ray.Direction, face.Mesh.FaceNormal(face.Face)) < 0
+		hitRecord.Material = face.Mesh.FaceMaterial(face.Face)
+		hitRecord.U, hitRecord.V = face.Mesh.UV(face.Face, u, v)
+		return true
+	}
+
+	return false
+}
+
+func (face *MeshTriangle) BoundingBox(box *AABB) bool {
+	var a, b, c = face.Mesh.FaceVertices(face.Face)
+	var padding = vmath.NewVector3(TriangleBoxPadding, TriangleBoxPadding, TriangleBoxPadding)
+
+	box.Set(a, a)
+	box.ExpandPoint(b)
+	box.ExpandPoint(c)
+	box.Min.Sub(padding)
+	box.Max.Add(padding)
+
+	return true
+}
+
+func (face *MeshTriangle) Clone() Hitable {
+	var f = new(MeshTriangle)
+	f.Mesh = face.Mesh
+	f.Face = face.Face
+	return f
+}
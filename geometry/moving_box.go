@@ -0,0 +1,70 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+)
+
+// Box that moves linearly between two positions, used for motion blur.
+// The corners are interpolated using the time of the ray, before Time0 and after Time1 the box is stopped.
+type MovingBox struct {
+	// Corners of the box at Time0.
+	Min0 *vmath.Vector3
+	Max0 *vmath.Vector3
+
+	// Corners of the box at Time1.
+	Min1 *vmath.Vector3
+	Max1 *vmath.Vector3
+
+	// Time when the movement starts and ends.
+	Time0 float64
+	Time1 float64
+
+	// Material used to render the box.
+	Material material.Material
+}
+
+func NewMovingBox(min0 *vmath.Vector3, max0 *vmath.Vector3, min1 *vmath.Vector3, max1 *vmath.Vector3, time0 float64, time1 float64, material material.Material) *MovingBox {
+	var b = new(MovingBox)
+	b.Min0 = min0
+	b.Max0 = max0
+	b.Min1 = min1
+	b.Max1 = max1
+	b.Time0 = time0
+	b.Time1 = time1
+	b.Material = material
+	return b
+}
+
+// Static box at the position of the moving box at a instant of time.
+func (b *MovingBox) box(time float64) *Box {
+	var t = motionFactor(time, b.Time0, b.Time1)
+	return NewBox(lerpVector(b.Min0, b.Min1, t), lerpVector(b.Max0, b.Max1, t), b.Material)
+}
+
+func (b *MovingBox) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	return b.box(ray.Time).Hit(ray, tmin, tmax, hitRecord)
+}
+
+func (b *MovingBox) Intervals(ray *vmath.Ray) []*Interval {
+	return b.box(ray.Time).Intervals(ray)
+}
+
+// The bounding box contains the box at the start and at the end of the movement.
+func (b *MovingBox) BoundingBox(box *AABB) bool {
+	box.Set(b.Min0, b.Max0)
+	box.Expand(NewAABB(b.Min1, b.Max1))
+	return true
+}
+
+func (o *MovingBox) Clone() Hitable {
+	var b = new(MovingBox)
+	b.Min0 = o.Min0.Clone()
+	b.Max0 = o.Max0.Clone()
+	b.Min1 = o.Min1.Clone()
+	b.Max1 = o.Max1.Clone()
+	b.Time0 = o.Time0
+	b.Time1 = o.Time1
+	b.Material = o.Material.Clone()
+	return b
+}
@@ -0,0 +1,263 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+)
+
+// Number of instants of the movement used to calculate the bounding box of moving instances.
+const MovingInstanceBoundSteps = 16
+
+// Instance with a transform animated between a start and a end transform, used for motion blur.
+// The components of the transforms are interpolated using the time of the ray, before Time0 and after Time1 the instance is stopped.
+// The scale of the transforms should not change sign, the transform cannot be inverted when the scale crosses zero.
+type MovingInstance struct {
+	// Object being instanced, defined in object space.
+	Object Hitable
+
+	// Transform from object space to world space at Time0 and at Time1.
+	Start *vmath.Transform
+	End *vmath.Transform
+
+	// Time when the movement starts and ends.
+	Time0 float64
+	Time1 float64
+}
+
+// Create new moving instance of a object.
+func NewMovingInstance(object Hitable, start *vmath.Transform, end *vmath.Transform, time0 float64, time1 float64) *MovingInstance {
+	var i = new(MovingInstance)
+	i.Object = object
+	i.Start = start
+	i.End = end
+	i.Time0 = time0
+	i.Time1 = time1
+	return i
+}
+
+// Static instance with the transform at a instant of time, used to calculate the bounding box.
+func (i *MovingInstance) instance(time float64) *Instance {
+	var transform = vmath.LerpTransform(i.Start, i.End, motionFactor(time, i.Time0, i.Time1))
+	return NewInstance(i.Object, transform.Matrix())
+}
+
+// Transform of the instance at the time of a ray.
+func (i *MovingInstance) frame(time float64) motionFrame {
+	var t = motionFactor(time, i.Time0, i.Time1)
+	var f motionFrame
+
+	f.Translation = *lerpVector(i.Start.Translation, i.End.Translation, t)
+	f.Scale = *lerpVector(i.Start.Scale, i.End.Scale, t)
+
+	var rotation = lerpVector(i.Start.Rotation, i.End.Rotation, t)
+	f.Sin.Set(math.Sin(rotation.X), math.Sin(rotation.Y), math.Sin(rotation.Z))
+	f.Cos.Set(math.Cos(rotation.X), math.Cos(rotation.Y), math.Cos(rotation.Z))
+
+	return f
+}
+
+func (i *MovingInstance) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var frame = i.frame(ray.Time)
+	if !i.Object.Hit(frame.objectRay(ray), tmin, tmax, hitRecord) {
+		return false
+	}
+
+	hitRecord.P = frame.worldPoint(hitRecord.P)
+	hitRecord.Normal = frame.worldNormal(hitRecord.Normal)
+	return true
+}
+
+// Returns no intervals if the object is not a solid.
+func (i *MovingInstance) Intervals(ray *vmath.Ray) []*Interval {
+	var solid, ok = i.Object.(Solid)
+	if !ok {
+		return nil
+	}
+
+	var frame = i.frame(ray.Time)
+	var intervals = solid.Intervals(frame.objectRay(ray))
+	for j := 0; j < len(intervals); j++ {
+		var surfaces = []*material.HitRecord{intervals[j].Enter, intervals[j].Exit}
+		for k := 0; k < len(surfaces); k++ {
+			surfaces[k].P = frame.worldPoint(surfaces[k].P)
+			surfaces[k].Normal = frame.worldNormal(surfaces[k].Normal)
+		}
+	}
+
+	return intervals
+}
+
+// Returns one if the object is not a volume.
+func (i *MovingInstance) Transmittance(ray *vmath.Ray, tmin float64, tmax float64) float64 {
+	var volume, ok = i.Object.(Volume)
+	if !ok {
+		return 1.0
+	}
+
+	var frame = i.frame(ray.Time)
+	return volume.Transmittance(frame.objectRay(ray), tmin, tmax)
+}
+
+// The bounding box contains the object at multiple instants of the movement.
+// Rotating points can leave the box between the instants, the box is padded by the maximum distance that they can move away.
+func (i *MovingInstance) BoundingBox(box *AABB) bool {
+	var local = NewEmptyAABB()
+	if !i.Object.BoundingBox(local) {
+		return false
+	}
+
+	box.Copy(NewEmptyAABB())
+
+	var step = NewEmptyAABB()
+	for s := 0; s <= MovingInstanceBoundSteps; s++ {
+		var time = i.Time0 + (i.Time1 - i.Time0) * float64(s) / MovingInstanceBoundSteps
+		i.instance(time).BoundingBox(step)
+		box.Expand(step)
+	}
+
+	// Farthest distance of the object from the origin of the object space, multiplied by the largest scale
+	var corner = vmath.NewVector3(math.Max(math.Abs(local.Min.X), math.Abs(local.Max.X)), math.Max(math.Abs(local.Min.Y), math.Abs(local.Max.Y)), math.Max(math.Abs(local.Min.Z), math.Abs(local.Max.Z)))
+	var radius = corner.Length() * math.Max(maxAbsComponent(i.Start.Scale), maxAbsComponent(i.End.Scale))
+
+	var rotation = i.End.Rotation.Clone()
+	rotation.Sub(i.Start.Rotation)
+	var angle = (math.Abs(rotation.X) + math.Abs(rotation.Y) + math.Abs(rotation.Z)) / MovingInstanceBoundSteps
+
+	var padding = radius * (1.0 - math.Cos(math.Min(angle, math.Pi) / 2.0))
+	box.Min.Sub(vmath.NewVector3(padding, padding, padding))
+	box.Max.Add(vmath.NewVector3(padding, padding, padding))
+
+	return true
+}
+
+// The instance is emissive if the object is a emissive light.
+func (i *MovingInstance) Emissive() bool {
+	var light, ok = i.Object.(Light)
+	return ok && light.Emissive()
+}
+
+// The direction is sampled in object space at the time of the ray and transformed back to world space.
+func (i *MovingInstance) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var frame = i.frame(time)
+	var direction = i.Object.(Light).SampleDirection(frame.objectPoint(origin), time)
+	return frame.worldVector(direction)
+}
+
+// The density of the object space direction is converted to world space solid angle using the jacobian of the transform at the time of the ray.
+func (i *MovingInstance) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var light, ok = i.Object.(Light)
+	if !ok {
+		return 0.0
+	}
+
+	var frame = i.frame(time)
+	var local = frame.objectVector(direction.UnitVector())
+	var length = local.Length()
+	if length == 0 {
+		return 0.0
+	}
+
+	// Determinant of the inverse transform, the rotation does not change the volume
+	var determinant = 1.0 / (frame.Scale.X * frame.Scale.Y * frame.Scale.Z)
+
+	var pdf = light.PDF(frame.objectPoint(origin), local, time)
+	return pdf * math.Abs(determinant) / (length * length * length)
+}
+
+// Largest absolute value of the components of a vector.
+func maxAbsComponent(v *vmath.Vector3) float64 {
+	return math.Max(math.Abs(v.X), math.Max(math.Abs(v.Y), math.Abs(v.Z)))
+}
+
+func (i *MovingInstance) Clone() Hitable {
+	var c = new(MovingInstance)
+	c.Object = i.Object.Clone()
+	c.Start = i.Start.Clone()
+	c.End = i.End.Clone()
+	c.Time0 = i.Time0
+	c.Time1 = i.Time1
+	return c
+}
+
+// Transform of a moving instance at a instant of time.
+// Points and vectors are transformed using the components directly, no matrix has to be built and inverted for each ray.
+type motionFrame struct {
+	Translation vmath.Vector3
+	Scale vmath.Vector3
+
+	// Sine and cosine of the rotation angles around the X, Y and Z axis.
+	Sin vmath.Vector3
+	Cos vmath.Vector3
+}
+
+// Rotate a vector around the X, Y and Z axis (Rz * Ry * Rx).
+func (f *motionFrame) rotate(v *vmath.Vector3) {
+	v.Y, v.Z = f.Cos.X * v.Y - f.Sin.X * v.Z, f.Sin.X * v.Y + f.Cos.X * v.Z
+	v.X, v.Z = f.Cos.Y * v.X + f.Sin.Y * v.Z, f.Cos.Y * v.Z - f.Sin.Y * v.X
+	v.X, v.Y = f.Cos.Z * v.X - f.Sin.Z * v.Y, f.Sin.Z * v.X + f.Cos.Z * v.Y
+}
+
+// Inverse of the rotation, around the Z, Y and X axis by the negative angles.
+func (f *motionFrame) unrotate(v *vmath.Vector3) {
+	v.X, v.Y = f.Cos.Z * v.X + f.Sin.Z * v.Y, f.Cos.Z * v.Y - f.Sin.Z * v.X
+	v.X, v.Z = f.Cos.Y * v.X - f.Sin.Y * v.Z, f.Sin.Y * v.X + f.Cos.Y * v.Z
+	v.Y, v.Z = f.Cos.X * v.Y + f.Sin.X * v.Z, f.Cos.X * v.Z - f.Sin.X * v.Y
+}
+
+// Divide a vector by the scale.
+func (f *motionFrame) unscale(v *vmath.Vector3) {
+	v.X /= f.Scale.X
+	v.Y /= f.Scale.Y
+	v.Z /= f.Scale.Z
+}
+
+// Transform a point from world space to object space.
+func (f *motionFrame) objectPoint(p *vmath.Vector3) *vmath.Vector3 {
+	var r = p.Clone()
+	r.Sub(&f.Translation)
+	f.unrotate(r)
+	f.unscale(r)
+	return r
+}
+
+// Transform a vector from world space to object space, translation is ignored.
+func (f *motionFrame) objectVector(v *vmath.Vector3) *vmath.Vector3 {
+	var r = v.Clone()
+	f.unrotate(r)
+	f.unscale(r)
+	return r
+}
+
+// Transform a ray from world space to object space.
+// The direction is not normalized, distances along the ray are the same in both spaces.
+func (f *motionFrame) objectRay(ray *vmath.Ray) *vmath.Ray {
+	return vmath.NewRayTime(f.objectPoint(ray.Origin), f.objectVector(ray.Direction), ray.Time)
+}
+
+// Transform a point from object space to world space.
+func (f *motionFrame) worldPoint(p *vmath.Vector3) *vmath.Vector3 {
+	var r = f.worldVector(p)
+	r.Add(&f.Translation)
+	return r
+}
+
+// Transform a vector from object space to world space, translation is ignored.
+func (f *motionFrame) worldVector(v *vmath.Vector3) *vmath.Vector3 {
+	var r = v.Clone()
+	r.Mul(&f.Scale)
+	f.rotate(r)
+	return r
+}
+
+// Transform a normal from object space to world space, using the inverse transpose of the rotation and scale.
+func (f *motionFrame) worldNormal(n *vmath.Vector3) *vmath.Vector3 {
+	var r = n.Clone()
+	f.unscale(r)
+	f.rotate(r)
+
+	if r.SquaredLength() > 0 {
+		r.Normalize()
+	}
+	return r
+}
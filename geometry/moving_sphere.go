@@ -0,0 +1,87 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+)
+
+// Sphere that moves linearly between two positions, used for motion blur.
+// The center is interpolated using the time of the ray, before Time0 and after Time1 the sphere is stopped.
+type MovingSphere struct {
+	Radius float64
+
+	// Center of the sphere at Time0 and at Time1.
+	Center0 *vmath.Vector3
+	Center1 *vmath.Vector3
+
+	// Time when the movement starts and ends.
+	Time0 float64
+	Time1 float64
+
+	// Material used to render the sphere.
+	Material material.Material
+}
+
+func NewMovingSphere(radius float64, center0 *vmath.Vector3, center1 *vmath.Vector3, time0 float64, time1 float64, material material.Material) *MovingSphere {
+	var s = new(MovingSphere)
+	s.Radius = radius
+	s.Center0 = center0
+	s.Center1 = center1
+	s.Time0 = time0
+	s.Time1 = time1
+	s.Material = material
+	return s
+}
+
+// Center of the sphere at a instant of time.
+func (s *MovingSphere) Center(time float64) *vmath.Vector3 {
+	return lerpVector(s.Center0, s.Center1, motionFactor(time, s.Time0, s.Time1))
+}
+
+// Static sphere at the position of the moving sphere at a instant of time.
+func (s *MovingSphere) sphere(time float64) *Sphere {
+	return NewSphere(s.Radius, s.Center(time), s.Material)
+}
+
+func (s *MovingSphere) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	return s.sphere(ray.Time).Hit(ray, tmin, tmax, hitRecord)
+}
+
+func (s *MovingSphere) Intervals(ray *vmath.Ray) []*Interval {
+	return s.sphere(ray.Time).Intervals(ray)
+}
+
+// The bounding box contains the sphere at the start and at the end of the movement.
+func (s *MovingSphere) BoundingBox(box *AABB) bool {
+	var end = NewEmptyAABB()
+	NewSphere(s.Radius, s.Center0, s.Material).BoundingBox(box)
+	NewSphere(s.Radius, s.Center1, s.Material).BoundingBox(end)
+	box.Expand(end)
+	return true
+}
+
+func (o *MovingSphere) Clone() Hitable {
+	var s = new(MovingSphere)
+	s.Radius = o.Radius
+	s.Center0 = o.Center0.Clone()
+	s.Center1 = o.Center1.Clone()
+	s.Time0 = o.Time0
+	s.Time1 = o.Time1
+	s.Material = o.Material.Clone()
+	return s
+}
+
+// Fraction of the movement done at a instant of time, clamped to the [0, 1] range.
+func motionFactor(time float64, time0 float64, time1 float64) float64 {
+	if time1 <= time0 || time <= time0 {
+		return 0.0
+	} else if time >= time1 {
+		return 1.0
+	}
+	return (time - time0) / (time1 - time0)
+}
+
+// Linear interpolation between two vectors.
+func lerpVector(a *vmath.Vector3, b *vmath.Vector3, t float64) *vmath.Vector3 {
+	return vmath.NewVector3(lerp(a.X, b.X, t), lerp(a.Y, b.Y, t), lerp(a.Z, b.Z, t))
+}
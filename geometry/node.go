@@ -0,0 +1,135 @@
+package geometry
+
+import (
+	"gotracer/vmath"
+)
+
+// Node of the scene graph, used to organize objects in a hierarchy.
+// Each node has a transform relative to its parent, moving a node moves all of its children.
+// The graph is flattened into a scene to be rendered.
+type Node struct {
+	// Name used to find the node, does not need to be unique.
+	Name string
+
+	// Transform relative to the parent node.
+	Transform *vmath.Matrix4
+
+	// Object placed in the node, can be nil for group nodes.
+	Object Hitable
+
+	// Child nodes, transformed by the transform of this node.
+	Children []*Node
+
+	// Parent of the node, nil for the root node.
+	Parent *Node
+
+	// Disabled nodes and their children are not added to the scene when the graph is flattened.
+	Enabled bool
+}
+
+// Create new empty group node.
+func NewNode(name string) *Node {
+	var n = new(Node)
+	n.Name = name
+	n.Transform = vmath.NewMatrix4()
+	n.Enabled = true
+	return n
+}
+
+// Create new node containing a object.
+func NewObjectNode(name string, object Hitable) *Node {
+	var n = NewNode(name)
+	n.Object = object
+	return n
+}
+
+// Add a child node, the child is removed from its previous parent.
+func (n *Node) Add(child *Node) {
+	if child.Parent != nil {
+		child.Parent.Remove(child)
+	}
+
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// Remove a child node, returns false if the node is not a child of this node.
+func (n *Node) Remove(child *Node) bool {
+	for i := 0; i < len(n.Children); i++ {
+		if n.Children[i] == child {
+			n.Children = append(n.Children[:i], n.Children[i + 1:]...)
+			child.Parent = nil
+			return true
+		}
+	}
+
+	return false
+}
+
+// Find a node by name in this node and its descendants (depth first).
+// Returns nil if no node was found.
+func (n *Node) Find(name string) *Node {
+	if n.Name == name {
+		return n
+	}
+
+	for i := 0; i < len(n.Children); i++ {
+		var found = n.Children[i].Find(name)
+		if found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// Transform from the node space to world space, combines the transforms of all parent nodes.
+func (n *Node) WorldTransform() *vmath.Matrix4 {
+	var transform = n.Transform.Clone()
+
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		transform = vmath.MultiplyMatrix4(parent.Transform, transform)
+	}
+
+	return transform
+}
+
+// Create a new scene with the objects of this node and its enabled descendants.
+// Objects are placed in world space using instances, the BVH of the scene is built.
+// The graph is kept as the root of the scene, to be flattened again after changes.
+func (n *Node) Flatten() *Scene {
+	var scene = NewScene()
+	scene.Root = n
+
+	var parent = vmath.NewMatrix4()
+	if n.Parent != nil {
+		parent = n.Parent.WorldTransform()
+	}
+
+	n.flatten(scene, parent)
+	scene.BuildBVH()
+
+	return scene
+}
+
+// Recursively add the objects of the node to the scene.
+// Objects without transform are added directly, other objects are added as instances.
+func (n *Node) flatten(scene *Scene, parent *vmath.Matrix4) {
+	if !n.Enabled {
+		return
+	}
+
+	var transform = vmath.MultiplyMatrix4(parent, n.Transform)
+
+	if n.Object != nil {
+		if transform.IsIdentity() {
+			scene.Add(n.Object)
+		} else {
+			scene.Add(NewInstance(n.Object, transform))
+		}
+	}
+
+	for i := 0; i < len(n.Children); i++ {
+		n.Children[i].flatten(scene, transform)
+	}
+}
@@ -0,0 +1,75 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+)
+
+// Infinite plane defined by a point and a normal.
+// The plane has no bounding box, it is tested separately from the BVH.
+type Plane struct {
+	// Point in the plane.
+	Point *vmath.Vector3
+
+	// Normal direction of the plane (normalized).
+	Normal *vmath.Vector3
+
+	// Material used to render the plane.
+	Material material.Material
+}
+
+func NewPlane(point *vmath.Vector3, normal *vmath.Vector3, material material.Material) *Plane {
+	var p = new(Plane)
+	p.Point = point
+	p.Normal = normal.UnitVector()
+	p.Material = material
+	return p
+}
+
+func (p *Plane) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var denominator = vmath.Dot(p.Normal, ray.Direction)
+	if math.Abs(denominator) < 1e-12 {
+		return false
+	}
+
+	var difference = p.Point.Clone()
+	difference.Sub(ray.Origin)
+
+	var t = vmath.Dot(difference, p.Normal) / denominator
+	if t >= tmax || t <= tmin {
+		return false
+	}
+
+	hitRecord.T = t
+	hitRecord.P = ray.PointAtParameter(t)
+	hitRecord.Normal = p.Normal.Clone()
+	hitRecord.FrontFace = denominator < 0
+	hitRecord.Material = p.Material
+	hitRecord.U, hitRecord.V = p.UV(hitRecord.P)
+	return true
+}
+
+// Texture coordinates are the distances along two perpendicular directions in the plane.
+// One unit in world space is mapped to the full texture, textures repeat across the plane.
+func (p *Plane) UV(point *vmath.Vector3) (float64, float64) {
+	var basis = vmath.NewONB(p.Normal)
+
+	var local = point.Clone()
+	local.Sub(p.Point)
+
+	return vmath.Dot(local, basis.U), vmath.Dot(local, basis.V)
+}
+
+// Infinite planes cannot be bounded.
+func (p *Plane) BoundingBox(box *AABB) bool {
+	return false
+}
+
+func (o *Plane) Clone() Hitable {
+	var p = new(Plane)
+	p.Point = o.Point.Clone()
+	p.Normal = o.Normal.Clone()
+	p.Material = o.Material.Clone()
+	return p
+}
@@ -0,0 +1,170 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+)
+
+// Axis aligned rectangle, lies in a plane perpendicular to one of the axis.
+// Used to build cornell boxes and rectangular area lights.
+type Rectangle struct {
+	// Axis perpendicular to the rectangle (0 for X, 1 for Y and 2 for Z), the normal points in the positive direction.
+	Axis int
+
+	// Minimum and maximum coordinates of the rectangle along the two other axis (in X, Y, Z order).
+	Min *vmath.Vector2
+	Max *vmath.Vector2
+
+	// Position of the rectangle along the perpendicular axis.
+	K float64
+
+	// Material used to render the rectangle.
+	Material material.Material
+}
+
+// Create new rectangle perpendicular to the Z axis.
+func NewXYRectangle(x0 float64, x1 float64, y0 float64, y1 float64, k float64, material material.Material) *Rectangle {
+	return NewRectangle(2, vmath.NewVector2(x0, y0), vmath.NewVector2(x1, y1), k, material)
+}
+
+// Create new rectangle perpendicular to the Y axis.
+func NewXZRectangle(x0 float64, x1 float64, z0 float64, z1 float64, k float64, material material.Material) *Rectangle {
+	return NewRectangle(1, vmath.NewVector2(x0, z0), vmath.NewVector2(x1, z1), k, material)
+}
+
+// Create new rectangle perpendicular to the X axis.
+func NewYZRectangle(y0 float64, y1 float64, z0 float64, z1 float64, k float64, material material.Material) *Rectangle {
+	return NewRectangle(0, vmath.NewVector2(y0, z0), vmath.NewVector2(y1, z1), k, material)
+}
+
+func NewRectangle(axis int, min *vmath.Vector2, max *vmath.Vector2, k float64, material material.Material) *Rectangle {
+	var r = new(Rectangle)
+	r.Axis = axis
+	r.Min = min
+	r.Max = max
+	r.K = k
+	r.Material = material
+	return r
+}
+
+// Axis of the plane of the rectangle, the two axis that are not perpendicular to it.
+func (r *Rectangle) planeAxis() (int, int) {
+	switch r.Axis {
+	case 0:
+		return 1, 2
+	case 1:
+		return 0, 2
+	}
+	return 0, 1
+}
+
+// Create a point from its coordinates in the plane of the rectangle.
+func (r *Rectangle) point(a float64, b float64) *vmath.Vector3 {
+	switch r.Axis {
+	case 0:
+		return vmath.NewVector3(r.K, a, b)
+	case 1:
+		return vmath.NewVector3(a, r.K, b)
+	}
+	return vmath.NewVector3(a, b, r.K)
+}
+
+// Intersect the ray with the rectangle, returns the distance and the coordinates of the intersection in the plane.
+func (r *Rectangle) Intersect(ray *vmath.Ray, tmin float64, tmax float64) (float64, float64, float64, bool) {
+	var direction = ray.Direction.Component(r.Axis)
+	if math.Abs(direction) < 1e-12 {
+		return 0, 0, 0, false
+	}
+
+	var t = (r.K - ray.Origin.Component(r.Axis)) / direction
+	if t >= tmax || t <= tmin {
+		return 0, 0, 0, false
+	}
+
+	var ia, ib = r.planeAxis()
+	var a = ray.Origin.Component(ia) + t * ray.Direction.Component(ia)
+	var b = ray.Origin.Component(ib) + t * ray.Direction.Component(ib)
+
+	if a < r.Min.X || a > r.Max.X || b < r.Min.Y || b > r.Max.Y {
+		return 0, 0, 0, false
+	}
+
+	return t, a, b, true
+}
+
+func (r *Rectangle) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var t, a, b, ok = r.Intersect(ray, tmin, tmax)
+	if !ok {
+		return false
+	}
+
+	hitRecord.T = t
+	hitRecord.P = ray.PointAtParameter(t)
+	hitRecord.Normal = axisNormal(r.Axis, 1.0)
+	hitRecord.FrontFace = ray.Direction.Component(r.Axis) < 0
+	hitRecord.Material = r.Material
+	hitRecord.U = (a - r.Min.X) / (r.Max.X - r.Min.X)
+	hitRecord.V = (b - r.Min.Y) / (r.Max.Y - r.Min.Y)
+	return true
+}
+
+// The box is padded along the perpendicular axis to avoid a flat box.
+func (r *Rectangle) BoundingBox(box *AABB) bool {
+	box.Set(r.point(r.Min.X, r.Min.Y), r.point(r.Max.X, r.Max.Y))
+
+	var padding = axisNormal(r.Axis, TriangleBoxPadding)
+	box.Min.Sub(padding)
+	box.Max.Add(padding)
+
+	return true
+}
+
+// Area of the rectangle.
+func (r *Rectangle) Area() float64 {
+	return (r.Max.X - r.Min.X) * (r.Max.Y - r.Min.Y)
+}
+
+func (r *Rectangle) Emissive() bool {
+	return material.IsEmissive(r.Material)
+}
+
+// Points are sampled uniformly over the area of the rectangle.
+func (r *Rectangle) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var a = r.Min.X + rand.Float64() * (r.Max.X - r.Min.X)
+	var b = r.Min.Y + rand.Float64() * (r.Max.Y - r.Min.Y)
+
+	var point = r.point(a, b)
+	point.Sub(origin)
+	return point
+}
+
+// The area density is converted to solid angle using the distance and the angle of the surface.
+func (r *Rectangle) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var t, _, _, ok = r.Intersect(vmath.NewRay(origin, direction), 0.0, math.MaxFloat64)
+	if !ok {
+		return 0.0
+	}
+
+	var length = direction.Length()
+	var distanceSq = t * t * length * length
+	var cosine = math.Abs(direction.Component(r.Axis)) / length
+	var area = r.Area()
+
+	if cosine <= 0 || area <= 0 {
+		return 0.0
+	}
+
+	return distanceSq / (cosine * area)
+}
+
+func (o *Rectangle) Clone() Hitable {
+	var r = new(Rectangle)
+	r.Axis = o.Axis
+	r.Min = o.Min.Clone()
+	r.Max = o.Max.Clone()
+	r.K = o.K
+	r.Material = o.Material.Clone()
+	return r
+}
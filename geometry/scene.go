@@ -3,12 +3,29 @@ package geometry
 import (
 	"gotracer/material"
 	"gotracer/vmath"
+	"math/rand"
 )
 
 // A scene (hittable list) contains hittable objects to be ray traced.
 // Works in the same way as a scene in game engines.
 type Scene struct {
 	List []Hitable
+
+	// Acceleration structure built from the objects in the list.
+	// Created by the BuildBVH method, if nil all objects in the list are tested linearly.
+	BVH *BVHNode
+
+	// Objects without bounding box that cannot be stored in the BVH.
+	Unbounded []Hitable
+
+	// Objects that emit light, used for direct light sampling.
+	Lights []Light
+
+	// Participating media, stored outside of the BVH and tested after the surfaces.
+	Volumes []Volume
+
+	// Scene graph used to create the scene, nil if the objects were added directly.
+	Root *Node
 }
 
 // Create new hittable list
@@ -17,19 +34,92 @@ func NewScene() *Scene {
 }
 
 // Add a hittable element to the list
+// Adding elements invalidates the BVH, it has to be built again.
+// Emissive objects that can be sampled are also added to the list of lights, volumes are added to the list of volumes.
 func (scene *Scene) Add(h Hitable) {
 	scene.List = append(scene.List, h)
+	scene.BVH = nil
+	scene.Unbounded = nil
+
+	if light, ok := h.(Light); ok && light.Emissive() {
+		scene.Lights = append(scene.Lights, light)
+	}
+
+	if IsVolume(h) {
+		scene.Volumes = append(scene.Volumes, h.(Volume))
+	}
+}
+
+// Build the BVH acceleration structure from the objects in the list.
+// Should be called after all objects are added to the scene, volumes are not stored in the BVH.
+func (scene *Scene) BuildBVH() {
+	var box = NewEmptyAABB()
+	var surfaces []Hitable
+
+	scene.Unbounded = nil
+
+	for i := 0; i < len(scene.List); i++ {
+		if IsVolume(scene.List[i]) {
+			continue
+		}
+
+		surfaces = append(surfaces, scene.List[i])
+		if !scene.List[i].BoundingBox(box) {
+			scene.Unbounded = append(scene.Unbounded, scene.List[i])
+		}
+	}
+
+	scene.BVH = NewBVH(surfaces)
 }
 
 // Hit iterates and tests all hittable object in the list.
+// The surfaces are tested first, the volumes are only tested up to the closest surface.
 func (scene *Scene) Hit(r *vmath.Ray, tmin float64, tmax float64, rec *material.HitRecord) bool {
+	var hitAnything = scene.HitSurface(r, tmin, tmax, rec)
+
+	var closestSoFar = tmax
+	if hitAnything {
+		closestSoFar = rec.T
+	}
+
+	var tempRec = material.NewHitRecord()
+
+	for i := 0; i < len(scene.Volumes); i++ {
+		if scene.Volumes[i].Hit(r, tmin, closestSoFar, tempRec) {
+			hitAnything = true
+			closestSoFar = tempRec.T
+			rec.Copy(tempRec)
+		}
+	}
+
+	return hitAnything
+}
+
+// Test the objects of the scene ignoring the volumes, used by shadow rays.
+// If the BVH was built it is used instead of testing all objects.
+func (scene *Scene) HitSurface(r *vmath.Ray, tmin float64, tmax float64, rec *material.HitRecord) bool {
 
 	var hitAnything = false
 	var closestSoFar = tmax
 	var tempRec = material.NewHitRecord()
 
-	for i := 0; i < len(scene.List); i++ {
-		if scene.List[i].Hit(r, tmin, closestSoFar, tempRec) {
+	var list = scene.List
+	if scene.BVH != nil {
+		list = scene.Unbounded
+
+		if scene.BVH.Hit(r, tmin, closestSoFar, tempRec) {
+			hitAnything = true
+			closestSoFar = tempRec.T
+			rec.Copy(tempRec)
+		}
+	}
+
+	for i := 0; i < len(list); i++ {
+		if IsVolume(list[i]) {
+			continue
+		}
+
+		if list[i].Hit(r, tmin, closestSoFar, tempRec) {
 			hitAnything = true
 			closestSoFar = tempRec.T
 			rec.Copy(tempRec)
@@ -39,13 +129,56 @@ func (scene *Scene) Hit(r *vmath.Ray, tmin float64, tmax float64, rec *material.
 	return hitAnything
 }
 
+// Fraction of the light that travels along the ray between tmin and tmax through the volumes of the scene.
+func (scene *Scene) Transmittance(r *vmath.Ray, tmin float64, tmax float64) float64 {
+	var transmittance = 1.0
+
+	for i := 0; i < len(scene.Volumes) && transmittance > 0; i++ {
+		transmittance *= scene.Volumes[i].Transmittance(r, tmin, tmax)
+	}
+
+	return transmittance
+}
+
+// Sample a random direction from the origin towards one of the lights in the scene.
+// The time of the ray is used to sample moving lights, returns nil if the scene has no lights.
+func (scene *Scene) SampleLight(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	if len(scene.Lights) == 0 {
+		return nil
+	}
+
+	return scene.Lights[rand.Intn(len(scene.Lights))].SampleDirection(origin, time)
+}
+
+// Probability density of a direction being sampled by SampleLight.
+// Lights are selected randomly, so the density is the average of the density of all lights.
+func (scene *Scene) LightPDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	if len(scene.Lights) == 0 {
+		return 0.0
+	}
+
+	var pdf = 0.0
+	for i := 0; i < len(scene.Lights); i++ {
+		pdf += scene.Lights[i].PDF(origin, direction, time)
+	}
+
+	return pdf / float64(len(scene.Lights))
+}
+
 // Clone the hittable list and the objects in the list
+// If the BVH was built it is also built for the new scene.
+// The scene graph is shared with the new scene.
 func (scene *Scene) Clone() *Scene {
 	var l = NewScene()
+	l.Root = scene.Root
 
 	for i := 0; i < len(scene.List); i++ {
 		l.Add(scene.List[i].Clone())
 	}
 
+	if scene.BVH != nil {
+		l.BuildBVH()
+	}
+
 	return l
 }
@@ -0,0 +1,81 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"sort"
+)
+
+// Solid interface indicates a closed object with a inside and a outside, that can be combined using CSG.
+type Solid interface {
+	Hitable
+
+	// Calculate all intervals along the ray where the ray is inside of the object, sorted by distance.
+	// Intervals are calculated for the whole line of the ray, including negative distances.
+	Intervals(ray *vmath.Ray) []*Interval
+}
+
+// Interval along a ray where the ray is inside of a solid.
+// The normals of the hit records point outside of the solid.
+type Interval struct {
+	// Surface where the ray enters the solid.
+	Enter *material.HitRecord
+
+	// Surface where the ray leaves the solid.
+	Exit *material.HitRecord
+}
+
+// Create new interval from the enter and exit surfaces.
+func NewInterval(enter *material.HitRecord, exit *material.HitRecord) *Interval {
+	var i = new(Interval)
+	i.Enter = enter
+	i.Exit = exit
+	return i
+}
+
+// Check if a object can be used as a solid.
+// Instances (and moving instances) are solids if the object that they transform is a solid.
+func IsSolid(h Hitable) bool {
+	if instance, ok := h.(*Instance); ok {
+		return IsSolid(instance.Object)
+	}
+	if instance, ok := h.(*MovingInstance); ok {
+		return IsSolid(instance.Object)
+	}
+
+	var _, ok = h.(Solid)
+	return ok
+}
+
+// Create intervals from a list of surface hits, the hits are sorted and paired in enter and exit surfaces.
+// If the number of hits is odd (e.g. a ray tangent to the surface) the last hit is ignored.
+func pairIntervals(hits []*material.HitRecord) []*Interval {
+	sort.Slice(hits, func(i int, j int) bool {
+		return hits[i].T < hits[j].T
+	})
+
+	var intervals []*Interval
+	for i := 0; i + 1 < len(hits); i += 2 {
+		intervals = append(intervals, NewInterval(hits[i], hits[i + 1]))
+	}
+
+	return intervals
+}
+
+// Find the first surface of a list of intervals inside of the distance range.
+// The result is copied to the hit record provided.
+func hitIntervals(intervals []*Interval, ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	for i := 0; i < len(intervals); i++ {
+		var surfaces = []*material.HitRecord{intervals[i].Enter, intervals[i].Exit}
+
+		for j := 0; j < len(surfaces); j++ {
+			if surfaces[j].T > tmin && surfaces[j].T < tmax {
+				hitRecord.Copy(surfaces[j])
+				hitRecord.FrontFace = vmath.Dot(ray.Direction, hitRecord.Normal) < 0
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -3,8 +3,9 @@ package geometry
 import (
 	"gotracer/material"
 	"gotracer/vmath"
+	"math"
+	"math/rand"
 )
-import "math"
 
 // Sphere is hitable object represented by a center point and a radius.
 // The sphere object has a material attached to it.
@@ -43,12 +44,7 @@ func (s *Sphere) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *mate
 		var temp = (-b - math.Sqrt(discriminant)) / a
 
 		if temp < tmax && temp > tmin {
-			hitRecord.T = temp
-			hitRecord.P = ray.PointAtParameter(temp)
-			hitRecord.Normal = hitRecord.P.Clone()
-			hitRecord.Normal.Sub(s.Center)
-			hitRecord.Normal.DivideScalar(s.Radius)
-			hitRecord.Material = s.Material
+			s.setHitRecord(ray, temp, hitRecord)
 			return true
 		}
 
@@ -56,12 +52,7 @@ func (s *Sphere) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *mate
 		temp = (-b + math.Sqrt(discriminant)) / a
 
 		if temp < tmax && temp > tmin {
-			hitRecord.T = temp
-			hitRecord.P = ray.PointAtParameter(temp)
-			hitRecord.Normal = hitRecord.P.Clone()
-			hitRecord.Normal.Sub(s.Center)
-			hitRecord.Normal.DivideScalar(s.Radius)
-			hitRecord.Material = s.Material
+			s.setHitRecord(ray, temp, hitRecord)
 			return true
 		}
 		
@@ -70,6 +61,106 @@ func (s *Sphere) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *mate
 	return false
 }
 
+// Fill the hit record with the surface of the sphere at a distance along the ray.
+func (s *Sphere) setHitRecord(ray *vmath.Ray, t float64, hitRecord *material.HitRecord) {
+	hitRecord.T = t
+	hitRecord.P = ray.PointAtParameter(t)
+	hitRecord.Normal = hitRecord.P.Clone()
+	hitRecord.Normal.Sub(s.Center)
+	hitRecord.Normal.DivideScalar(s.Radius)
+	hitRecord.FrontFace = vmath.Dot(ray.Direction, hitRecord.Normal) < 0
+	hitRecord.Material = s.Material
+	hitRecord.U, hitRecord.V = SphereUV(hitRecord.Normal)
+}
+
+// The ray is inside of the sphere between the two roots.
+func (s *Sphere) Intervals(ray *vmath.Ray) []*Interval {
+	var oc = ray.Origin.Clone()
+	oc.Sub(s.Center)
+
+	var a = vmath.Dot(ray.Direction, ray.Direction)
+	var b = vmath.Dot(oc, ray.Direction)
+	var c = vmath.Dot(oc, oc) - s.Radius * s.Radius
+	var discriminant = b * b - a * c
+
+	if discriminant <= 0 {
+		return nil
+	}
+
+	var enter = material.NewHitRecord()
+	s.setHitRecord(ray, (-b - math.Sqrt(discriminant)) / a, enter)
+
+	var exit = material.NewHitRecord()
+	s.setHitRecord(ray, (-b + math.Sqrt(discriminant)) / a, exit)
+
+	return []*Interval{NewInterval(enter, exit)}
+}
+
+// Calculate the spherical texture coordinates of a point in the surface of a unit sphere.
+// U is the angle around the Y axis and V the angle from the bottom to the top of the sphere.
+func SphereUV(p *vmath.Vector3) (float64, float64) {
+	var theta = math.Acos(math.Max(-1.0, math.Min(-p.Y, 1.0)))
+	var phi = math.Atan2(-p.Z, p.X) + math.Pi
+	return phi / (2.0 * math.Pi), theta / math.Pi
+}
+
+func (s *Sphere) BoundingBox(box *AABB) bool {
+	var radius = vmath.NewVector3(s.Radius, s.Radius, s.Radius)
+
+	box.Min.Copy(s.Center)
+	box.Min.Sub(radius)
+	box.Max.Copy(s.Center)
+	box.Max.Add(radius)
+
+	return true
+}
+
+func (s *Sphere) Emissive() bool {
+	return material.IsEmissive(s.Material)
+}
+
+// Directions are sampled uniformly inside of the cone that contains the sphere as seen from the origin.
+// If the origin is inside of the sphere every direction hits it, directions are sampled uniformly.
+func (s *Sphere) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var direction = s.Center.Clone()
+	direction.Sub(origin)
+
+	var distanceSq = direction.SquaredLength()
+	var radiusSq = s.Radius * s.Radius
+
+	if distanceSq <= radiusSq {
+		return vmath.RandomUnitVector()
+	}
+
+	var cosThetaMax = math.Sqrt(1.0 - radiusSq / distanceSq)
+	var z = 1.0 + rand.Float64() * (cosThetaMax - 1.0)
+	var phi = 2.0 * math.Pi * rand.Float64()
+	var r = math.Sqrt(math.Max(0.0, 1.0 - z * z))
+
+	return vmath.NewONB(direction).Local(r * math.Cos(phi), r * math.Sin(phi), z)
+}
+
+func (s *Sphere) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var center = s.Center.Clone()
+	center.Sub(origin)
+
+	var distanceSq = center.SquaredLength()
+	var radiusSq = s.Radius * s.Radius
+
+	if distanceSq <= radiusSq {
+		return 1.0 / (4.0 * math.Pi)
+	}
+
+	var cosThetaMax = math.Sqrt(1.0 - radiusSq / distanceSq)
+	var cosine = vmath.Dot(center, direction) / (math.Sqrt(distanceSq) * direction.Length())
+
+	if cosine < cosThetaMax {
+		return 0.0
+	}
+
+	return 1.0 / (2.0 * math.Pi * (1.0 - cosThetaMax))
+}
+
 func (o *Sphere) Clone() Hitable {
 	var s = new(Sphere)
 	s.Radius = o.Radius
@@ -0,0 +1,178 @@
+package geometry
+
+import (
+	"gotracer/material"
+	"gotracer/vmath"
+	"math"
+)
+
+// Torus lying in the XZ plane, with the Y axis passing through its hole.
+// Defined by the distance from the center to the center of the tube and the radius of the tube.
+type Torus struct {
+	Center *vmath.Vector3
+
+	// Distance from the center of the torus to the center of the tube.
+	MajorRadius float64
+
+	// Radius of the tube.
+	MinorRadius float64
+
+	// Material used to render the torus.
+	Material material.Material
+}
+
+func NewTorus(center *vmath.Vector3, majorRadius float64, minorRadius float64, material material.Material) *Torus {
+	var t = new(Torus)
+	t.Center = center
+	t.MajorRadius = majorRadius
+	t.MinorRadius = minorRadius
+	t.Material = material
+	return t
+}
+
+// Intersect the line of the ray with the torus, returns the distances of all intersections.
+// The intersection is a quartic equation, roots are refined with newton iterations to reduce precision problems.
+func (t *Torus) intersections(ray *vmath.Ray) []float64 {
+	var length = ray.Direction.Length()
+	if length == 0 {
+		return nil
+	}
+
+	var d = ray.Direction.Clone()
+	d.DivideScalar(length)
+
+	var o = ray.Origin.Clone()
+	o.Sub(t.Center)
+
+	// Move the origin close to the bounding sphere of the torus, no intersection can be skipped.
+	var start = math.Max(0.0, o.Length() - (t.MajorRadius + t.MinorRadius))
+	o.X += d.X * start
+	o.Y += d.Y * start
+	o.Z += d.Z * start
+
+	var R = t.MajorRadius * t.MajorRadius
+	var g = vmath.Dot(o, o) + R - t.MinorRadius * t.MinorRadius
+	var h = vmath.Dot(o, d)
+
+	var a3 = 4.0 * h
+	var a2 = 2.0 * g + 4.0 * h * h - 4.0 * R * (d.X * d.X + d.Z * d.Z)
+	var a1 = 4.0 * h * g - 8.0 * R * (o.X * d.X + o.Z * d.Z)
+	var a0 = g * g - 4.0 * R * (o.X * o.X + o.Z * o.Z)
+
+	var roots = vmath.SolveQuartic(1.0, a3, a2, a1, a0)
+
+	for i := 0; i < len(roots); i++ {
+		var x = roots[i]
+
+		for j := 0; j < 4; j++ {
+			var f = (((x + a3) * x + a2) * x + a1) * x + a0
+			var df = ((4.0 * x + 3.0 * a3) * x + 2.0 * a2) * x + a1
+			if df == 0 {
+				break
+			}
+			x -= f / df
+		}
+
+		roots[i] = (x + start) / length
+	}
+
+	return roots
+}
+
+// Intersect the ray with the torus, returns the distance to the closest intersection.
+func (t *Torus) Intersect(ray *vmath.Ray, tmin float64, tmax float64) (float64, bool) {
+	var distances = t.intersections(ray)
+
+	var closest = tmax
+	var hit = false
+
+	for i := 0; i < len(distances); i++ {
+		if distances[i] > tmin && distances[i] < closest {
+			closest = distances[i]
+			hit = true
+		}
+	}
+
+	return closest, hit
+}
+
+func (t *Torus) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
+	var distance, ok = t.Intersect(ray, tmin, tmax)
+	if !ok {
+		return false
+	}
+
+	t.setHitRecord(ray, distance, hitRecord)
+	return true
+}
+
+// Fill the hit record with the surface of the torus at a distance along the ray.
+func (t *Torus) setHitRecord(ray *vmath.Ray, distance float64, hitRecord *material.HitRecord) {
+	hitRecord.T = distance
+	hitRecord.P = ray.PointAtParameter(distance)
+	hitRecord.Normal = t.Normal(hitRecord.P)
+	hitRecord.FrontFace = vmath.Dot(ray.Direction, hitRecord.Normal) < 0
+	hitRecord.Material = t.Material
+	hitRecord.U, hitRecord.V = t.UV(hitRecord.P)
+}
+
+// Intersections are paired in intervals, the ray can cross the tube twice.
+func (t *Torus) Intervals(ray *vmath.Ray) []*Interval {
+	var distances = t.intersections(ray)
+
+	var hits []*material.HitRecord
+	for i := 0; i < len(distances); i++ {
+		var hit = material.NewHitRecord()
+		t.setHitRecord(ray, distances[i], hit)
+		hits = append(hits, hit)
+	}
+
+	return pairIntervals(hits)
+}
+
+// Normal of a point in the surface, direction from the center of the tube to the point.
+func (t *Torus) Normal(p *vmath.Vector3) *vmath.Vector3 {
+	var local = p.Clone()
+	local.Sub(t.Center)
+
+	var ring = vmath.NewVector3(local.X, 0.0, local.Z)
+	if ring.SquaredLength() > 0 {
+		ring.Normalize()
+	}
+	ring.MulScalar(t.MajorRadius)
+
+	local.Sub(ring)
+	if local.SquaredLength() > 0 {
+		local.Normalize()
+	}
+	return local
+}
+
+// Texture coordinates of a point in the torus.
+// U is the angle around the Y axis and V the angle around the tube.
+func (t *Torus) UV(p *vmath.Vector3) (float64, float64) {
+	var local = p.Clone()
+	local.Sub(t.Center)
+
+	var distance = math.Sqrt(local.X * local.X + local.Z * local.Z)
+
+	var u = (math.Atan2(-local.Z, local.X) + math.Pi) / (2.0 * math.Pi)
+	var v = (math.Atan2(local.Y, distance - t.MajorRadius) + math.Pi) / (2.0 * math.Pi)
+	return u, v
+}
+
+func (t *Torus) BoundingBox(box *AABB) bool {
+	var extent = t.MajorRadius + t.MinorRadius
+	box.Min.Set(t.Center.X - extent, t.Center.Y - t.MinorRadius, t.Center.Z - extent)
+	box.Max.Set(t.Center.X + extent, t.Center.Y + t.MinorRadius, t.Center.Z + extent)
+	return true
+}
+
+func (o *Torus) Clone() Hitable {
+	var t = new(Torus)
+	t.Center = o.Center.Clone()
+	t.MajorRadius = o.MajorRadius
+	t.MinorRadius = o.MinorRadius
+	t.Material = o.Material.Clone()
+	return t
+}
@@ -3,9 +3,26 @@ package geometry
 import (
 	"gotracer/material"
 	"gotracer/vmath"
+	"math"
+	"math/rand"
+)
+
+// Cull mode indicates which faces of a triangle are ignored by the ray intersection.
+type CullMode int
+
+const (
+	// Both faces of the triangle are visible.
+	CullNone CullMode = iota
+
+	// Back faces are ignored, the triangle is only visible from the front.
+	CullBack
+
+	// Front faces are ignored, the triangle is only visible from the back.
+	CullFront
 )
 
 // Triangle is hittable object represented by three points.
+// Triangles are double sided by default, the front face is the one where the points are in counter clockwise order.
 type Triangle struct {
 	A *vmath.Vector3
 	B *vmath.Vector3
@@ -14,6 +31,21 @@ type Triangle struct {
 	// Normal direction of the triangle plane
 	Normal *vmath.Vector3
 
+	// Texture coordinates of each vertex.
+	// If not set the barycentric coordinates of the hit point are used.
+	UVA *vmath.Vector2
+	UVB *vmath.Vector2
+	UVC *vmath.Vector2
+
+	// Normal of each vertex, interpolated for smooth shading.
+	// If not set the normal of the triangle plane is used.
+	NormalA *vmath.Vector3
+	NormalB *vmath.Vector3
+	NormalC *vmath.Vector3
+
+	// Faces of the triangle ignored by the ray intersection.
+	Cull CullMode
+
 	// Material used to render the sphere.
 	Material material.Material
 }
@@ -47,46 +79,173 @@ func (triangle *Triangle) GetNormal() {
 	}
 }
 
-// https://en.wikipedia.org/wiki/M%C3%B6ller%E2%80%93Trumbore_intersection_algorithm
 func (triangle *Triangle) Hit(ray *vmath.Ray, tmin float64, tmax float64, hitRecord *material.HitRecord) bool {
-	var v0v1 *vmath.Vector3 = triangle.B.Clone()
-	v0v1.Sub(triangle.A)
+	var t, u, v, ok = triangle.Intersect(ray, tmin, tmax, triangle.Cull)
+
+	if ok {
+		hitRecord.T = t
+		hitRecord.P = ray.PointAtParameter(t)
+		hitRecord.Normal = triangle.ShadingNormal(u, v)
+		hitRecord.FrontFace = vmath.Dot(ray.Direction, triangle.Normal) < 0
+		hitRecord.Material = triangle.Material
+		hitRecord.U, hitRecord.V = triangle.UV(u, v)
+		return true
+	}
+
+	return false
+}
+
+// Calculate the texture coordinates from the barycentric coordinates of a point (relative to B and C).
+func (triangle *Triangle) UV(u float64, v float64) (float64, float64) {
+	if triangle.UVA == nil || triangle.UVB == nil || triangle.UVC == nil {
+		return u, v
+	}
+
+	var w = 1.0 - u - v
+	return w * triangle.UVA.X + u * triangle.UVB.X + v * triangle.UVC.X, w * triangle.UVA.Y + u * triangle.UVB.Y + v * triangle.UVC.Y
+}
+
+// Calculate the shading normal from the barycentric coordinates of a point (relative to B and C).
+func (triangle *Triangle) ShadingNormal(u float64, v float64) *vmath.Vector3 {
+	if triangle.NormalA == nil || triangle.NormalB == nil || triangle.NormalC == nil {
+		return triangle.Normal.Clone()
+	}
+
+	var normal = triangle.NormalA.Clone()
+	normal.MulScalar(1.0 - u - v)
+
+	var b = triangle.NormalB.Clone()
+	b.MulScalar(u)
+
+	var c = triangle.NormalC.Clone()
+	c.MulScalar(v)
+
+	normal.Add(b)
+	normal.Add(c)
+
+	if normal.SquaredLength() == 0 {
+		return triangle.Normal.Clone()
+	}
+
+	normal.Normalize()
+	return normal
+}
+
+// Intersect the ray with the triangle, returns the distance and the barycentric coordinates (relative to B and C) of the intersection.
+// The cull mode selects the faces that are ignored.
+// https://en.wikipedia.org/wiki/M%C3%B6ller%E2%80%93Trumbore_intersection_algorithm
+func (triangle *Triangle) Intersect(ray *vmath.Ray, tmin float64, tmax float64, cull CullMode) (float64, float64, float64, bool) {
+	return IntersectTriangle(triangle.A, triangle.B, triangle.C, ray, tmin, tmax, cull)
+}
+
+// Intersect a ray with the triangle formed by three points, used by triangles and meshes.
+// Returns the distance and the barycentric coordinates (relative to B and C) of the intersection.
+func IntersectTriangle(a *vmath.Vector3, b *vmath.Vector3, c *vmath.Vector3, ray *vmath.Ray, tmin float64, tmax float64, cull CullMode) (float64, float64, float64, bool) {
+	var v0v1 *vmath.Vector3 = b.Clone()
+	v0v1.Sub(a)
 
-	var v0v2 *vmath.Vector3 = triangle.C.Clone()
-	v0v2.Sub(triangle.A)
+	var v0v2 *vmath.Vector3 = c.Clone()
+	v0v2.Sub(a)
 
 	var pvec *vmath.Vector3 = vmath.Cross(ray.Direction, v0v2)
 	var det = vmath.Dot(v0v1, pvec)
-	if det < 0.000001 {
-		return false
+
+	// The determinant is positive when the front face is hit and close to zero when the ray is parallel to the triangle
+	if math.Abs(det) < 0.000001 || (cull == CullBack && det < 0) || (cull == CullFront && det > 0) {
+		return 0, 0, 0, false
 	}
 
 	var invDet = 1.0 / det
 	var tvec *vmath.Vector3 = ray.Origin.Clone()
-	tvec.Sub(triangle.A)
+	tvec.Sub(a)
 
 	var u = vmath.Dot(tvec, pvec) * invDet
 	if u < 0 || u > 1 {
-		return false
+		return 0, 0, 0, false
 	}
 
 	var qvec *vmath.Vector3 = vmath.Cross(tvec, v0v1)
 	var v = vmath.Dot(ray.Direction, qvec) * invDet
 	if v < 0 || u + v > 1 {
-		return false
+		return 0, 0, 0, false
 	}
 
 	var t = vmath.Dot(v0v2, qvec) * invDet
 
 	if t < tmax && t > tmin {
-		hitRecord.T = t
-		hitRecord.P = ray.PointAtParameter(t)
-		hitRecord.Normal = triangle.Normal.Clone()
-		hitRecord.Material = triangle.Material
-		return true
+		return t, u, v, true
 	}
 
-	return false
+	return 0, 0, 0, false
+}
+
+// Area of the triangle.
+func (triangle *Triangle) Area() float64 {
+	var ab = triangle.B.Clone()
+	ab.Sub(triangle.A)
+
+	var ac = triangle.C.Clone()
+	ac.Sub(triangle.A)
+
+	return vmath.Cross(ab, ac).Length() / 2.0
+}
+
+func (triangle *Triangle) Emissive() bool {
+	return material.IsEmissive(triangle.Material)
+}
+
+// Points are sampled uniformly over the area of the triangle.
+func (triangle *Triangle) SampleDirection(origin *vmath.Vector3, time float64) *vmath.Vector3 {
+	var r1 = math.Sqrt(rand.Float64())
+	var r2 = rand.Float64()
+
+	var a = triangle.A.Clone()
+	a.MulScalar(1.0 - r1)
+
+	var b = triangle.B.Clone()
+	b.MulScalar(r1 * (1.0 - r2))
+
+	var c = triangle.C.Clone()
+	c.MulScalar(r1 * r2)
+
+	a.Add(b)
+	a.Add(c)
+	a.Sub(origin)
+	return a
+}
+
+// The area density is converted to solid angle using the distance and the angle of the surface.
+func (triangle *Triangle) PDF(origin *vmath.Vector3, direction *vmath.Vector3, time float64) float64 {
+	var t, _, _, ok = triangle.Intersect(vmath.NewRay(origin, direction), 0.0, math.MaxFloat64, CullNone)
+	if !ok {
+		return 0.0
+	}
+
+	var length = direction.Length()
+	var distanceSq = t * t * length * length
+	var cosine = math.Abs(vmath.Dot(direction, triangle.Normal)) / length
+	var area = triangle.Area()
+
+	if cosine <= 0 || area <= 0 {
+		return 0.0
+	}
+
+	return distanceSq / (cosine * area)
+}
+
+// Padding added to the triangle bounding box, to avoid flat boxes for axis aligned triangles.
+const TriangleBoxPadding = 1e-6
+
+func (triangle *Triangle) BoundingBox(box *AABB) bool {
+	var padding = vmath.NewVector3(TriangleBoxPadding, TriangleBoxPadding, TriangleBoxPadding)
+
+	box.Set(triangle.A, triangle.A)
+	box.ExpandPoint(triangle.B)
+	box.ExpandPoint(triangle.C)
+	box.Min.Sub(padding)
+	box.Max.Add(padding)
+
+	return true
 }
 
 func (triangle *Triangle) Clone() Hitable {
@@ -95,6 +254,19 @@ func (triangle *Triangle) Clone() Hitable {
 	s.B = triangle.B.Clone()
 	s.C = triangle.C.Clone()
 	s.Normal = triangle.Normal.Clone()
+	s.Cull = triangle.Cull
 	s.Material = triangle.Material.Clone()
+
+	if triangle.UVA != nil && triangle.UVB != nil && triangle.UVC != nil {
+		s.UVA = triangle.UVA.Clone()
+		s.UVB = triangle.UVB.Clone()
+		s.UVC = triangle.UVC.Clone()
+	}
+
+	if triangle.NormalA != nil && triangle.NormalB != nil && triangle.NormalC != nil {
+		s.NormalA = triangle.NormalA.Clone()
+		s.NormalB = triangle.NormalB.Clone()
+		s.NormalC = triangle.NormalC.Clone()
+	}
 	return s
 }
\ No newline at end of file
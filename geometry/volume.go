@@ -0,0 +1,28 @@
+package geometry
+
+import (
+	"gotracer/vmath"
+)
+
+// Volume interface indicates a participating medium, rays travelling through it can be scattered or absorbed.
+// Volumes are not treated as surfaces by shadow rays, the light that reaches the surface is attenuated by their transmittance.
+type Volume interface {
+	Hitable
+
+	// Fraction of the light that travels along the ray between tmin and tmax without being scattered or absorbed.
+	Transmittance(ray *vmath.Ray, tmin float64, tmax float64) float64
+}
+
+// Check if a object is a volume.
+// Instances (and moving instances) are volumes if the object that they transform is a volume.
+func IsVolume(h Hitable) bool {
+	if instance, ok := h.(*Instance); ok {
+		return IsVolume(instance.Object)
+	}
+	if instance, ok := h.(*MovingInstance); ok {
+		return IsVolume(instance.Object)
+	}
+
+	var _, ok = h.(Volume)
+	return ok
+}
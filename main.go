@@ -1,16 +1,18 @@
 package main
 
 import (
-	"bytes"
+	"flag"
 	"github.com/faiface/pixel"
-	"github.com/faiface/pixel/pixelgl"
-	"github.com/sheenobu/go-obj/obj"
-	"golang.org/x/image/colornames"
+	"gotracer/film"
 	"gotracer/geometry"
 	"gotracer/camera"
 	"gotracer/material"
+	"gotracer/output"
+	"gotracer/scenefile"
+	"gotracer/texture"
+	"gotracer/tonemap"
 	"gotracer/vmath"
-	"io/ioutil"
+	"gotracer/wavefront"
 	"log"
 	"math"
 	"math/rand"
@@ -31,37 +33,94 @@ const MaxDepth int64 = 50
 // Minimum distance to be considerd for ray collision
 const MinDistance float64 = 1e-5
 
+// Exponent of the multiple importance sampling heuristic, 1 for the balance heuristic and 2 for the power heuristic
+const MISPower float64 = 2.0
+
 //If true multiple rays are casted and blended for each pixel
 const Antialiasing = false
 
-//If true the last n Frames are blended
+//If true the samples of each frame are accumulated progressively until the camera changes
 const TemporalFilter = true
-const TemporalFilterSamples = 32
 
 //If true splits the image generation into threads
 const Multithreaded = true
 const MultithreadedTheads = 4
 const MultithreadDataCopies = false
 
-// Temporal acomulation buffers
-var Frames []*pixel.PictureData
-
 // Scene and camera copies for threads
 var SceneCopies []*geometry.Scene
-var CameraCopies []*camera.CameraDefocus
+var CameraCopies []camera.Camera
+
+// If true the image is rendered offline and written to a file, no window is created.
+var Headless = flag.Bool("headless", false, "render offline and write the result to the output file")
+
+// File where the headless render is written, the format is selected by the extension (.png, .jpg, .ppm, .pfm, .hdr or .exr).
+var Output = flag.String("output", "render.png", "output file for the headless render (.png, .jpg, .ppm, .pfm, .hdr or .exr)")
+
+// Quality of the JPEG output images.
+var Quality = flag.Int("quality", output.DefaultQuality, "quality of JPEG output images (1 to 100)")
+
+// If true PNG output images are written with 16 bits per channel.
+var Depth16 = flag.Bool("depth16", false, "write PNG output images with 16 bits per channel")
+
+// Number of samples per pixel accumulated in headless mode.
+var Samples = flag.Int("samples", 32, "samples per pixel for the headless render")
+
+// Scene description file to be rendered, if empty the default scene is used.
+var SceneFile = flag.String("scene", "", "scene description file (JSON)")
+
+// Tone mapping operator used to display the image.
+var ToneMapOperator = flag.String("tonemap", "aces", "tone mapping operator (clamp, reinhard, reinhard-extended, aces or uncharted2)")
+
+// Exposure adjustment applied before tone mapping in stops.
+var Exposure = flag.Float64("exposure", 0.0, "exposure adjustment in stops (EV)")
+
+// Color temperature of the scene lighting used for white balance, 6500K is neutral.
+var WhiteBalance = flag.Float64("whitebalance", 6500.0, "white balance color temperature in Kelvin")
+
+// Tone mapper used to convert the rendered radiance into display colors.
+var ToneMapper *tonemap.ToneMapper
 
 func main() {
 	//runtime.GOMAXPROCS(8)
-	pixelgl.Run(run)
+	flag.Parse()
+
+	var operator, err = tonemap.OperatorByName(*ToneMapOperator)
+	CheckError(err)
+
+	ToneMapper = tonemap.NewToneMapper(operator)
+	ToneMapper.Exposure = *Exposure
+	ToneMapper.WhiteBalance = tonemap.WhiteBalanceTemperature(*WhiteBalance)
+
+	if *Headless {
+		RunHeadless(*Output, *Samples)
+	} else {
+		RunViewer()
+	}
 }
 
-func run() {
-	var bounds = pixel.R(0, 0, Width, Height)
-	var windowBounds = pixel.R(0, 0, Width * Upscale, Height * Upscale)
+// Load the scene and camera from the scene file.
+// If no scene file was specified the default scene and camera are created.
+func LoadScene(bounds pixel.Rect) (*geometry.Scene, camera.Camera) {
+	if *SceneFile != "" {
+		var scene, camera, err = scenefile.Load(*SceneFile, bounds)
+		CheckError(err)
+		return scene, camera
+	}
 
-	// Prepare the scene
+	// The shutter is open during the movement of the objects of the default scene
+	var cam = camera.NewCameraDefocusBounds(bounds)
+	cam.ShutterOpen = 0.0
+	cam.ShutterClose = 1.0
+
+	return CreateScene(), cam
+}
+
+// Create the default scene to be rendered.
+func CreateScene() *geometry.Scene {
 	var scene = geometry.NewScene()
-	scene.Add(geometry.NewSphere(500.0, vmath.NewVector3(0.0, -500.5, -1.0), material.NewLightMaterial(vmath.NewVector3(0.4, 0.7, 0.0))))
+	var ground = texture.NewCheckerTexture(texture.NewSolidTexture(vmath.NewVector3(0.2, 0.35, 0.0)), texture.NewSolidTexture(vmath.NewVector3(0.4, 0.7, 0.0)), 1.0)
+	scene.Add(geometry.NewPlane(vmath.NewVector3(0.0, -0.5, 0.0), vmath.NewVector3(0.0, 1.0, 0.0), material.NewLambertMaterialTexture(ground)))
 	scene.Add(geometry.NewSphere(0.5, vmath.NewVector3(-1.0, 0.0, -3.0), material.NewNormalMaterial()))
 	scene.Add(geometry.NewSphere(1.5, vmath.NewVector3(5.0, 1.0, -6.0), material.NewDieletricMaterial(1.3, vmath.NewVector3(0.90, 0.90, 0.90))))
 	scene.Add(geometry.NewSphere(1.5, vmath.NewVector3(-1.0, 1.0, -3.0), material.NewMetalMaterial(vmath.NewVector3(0.6, 0.6, 0.6), 0.1)))
@@ -69,7 +128,7 @@ func run() {
 	var min = 15.0
 	var distance = 30.0
 
-	//LoadOBJ(scene, "bunny.obj", material.NewLightMaterial(vmath.NewVector3(0.90, 0.9, 0.9)))
+	//CheckError(LoadOBJ(scene, "bunny.obj", material.NewLightMaterial(vmath.NewVector3(0.90, 0.9, 0.9))))
 
 	// Place random sphere objects
 	for i := 0; i < 40; i++ {
@@ -77,9 +136,12 @@ func run() {
 		var position = vmath.NewVector3(rand.Float64() * distance - min, radius - 0.5, rand.Float64() * distance - min)
 		scene.Add(geometry.NewSphere(radius, position, material.NewLightMaterial(vmath.NewRandomVector3(0.1, 1))))
 
+		// Metal spheres bounce while the camera shutter is open
 		radius = 0.4 + rand.Float64() * 0.2
 		position = vmath.NewVector3(rand.Float64() * distance - min, radius - 0.5, rand.Float64() * distance - min)
-		scene.Add(geometry.NewSphere(radius, position, material.NewMetalMaterial(vmath.NewRandomVector3(0.1, 1), rand.Float64())))
+		var bounce = position.Clone()
+		bounce.Y += rand.Float64() * 0.5
+		scene.Add(geometry.NewMovingSphere(radius, position, bounce, 0.0, 1.0, material.NewMetalMaterial(vmath.NewRandomVector3(0.1, 1), rand.Float64())))
 
 		radius = 0.4 + rand.Float64() * 0.2
 		position = vmath.NewVector3(rand.Float64() * distance - min, radius - 0.5, rand.Float64() * distance - min)
@@ -112,144 +174,82 @@ func run() {
 		bmax.Add(halfSize)
 		scene.Add(geometry.NewBox(bmin, bmax, material.NewLightMaterial(vmath.NewRandomVector3(0.1, 1))))
 
-		position = vmath.NewVector3(rand.Float64() * distance - min, halfSize.Y - 0.5, rand.Float64()*distance-min)
-		bmin = position.Clone()
-		bmin.Sub(halfSize)
-		bmax = position.Clone()
-		bmax.Add(halfSize)
-		scene.Add(geometry.NewBox(bmin, bmax, material.NewMetalMaterial(vmath.NewRandomVector3(0.6, 1), 0.0)))
+		// Metal boxes are rotated around the vertical axis using a instance
+		var box = geometry.NewBox(vmath.NewVector3(-halfSize.X, -halfSize.Y, -halfSize.Z), halfSize.Clone(), material.NewMetalMaterial(vmath.NewRandomVector3(0.6, 1), 0.0))
+		var transform = vmath.NewTranslationMatrix4(rand.Float64() * distance - min, halfSize.Y - 0.5, rand.Float64() * distance - min)
+		transform.Multiply(vmath.NewRotationYMatrix4(rand.Float64() * math.Pi))
+		scene.Add(geometry.NewInstance(box, transform))
 	}
 
-	var camera = camera.NewCameraDefocusBounds(bounds)
+	// Build the acceleration structure after all objects are placed
+	scene.BuildBVH()
 
+	return scene
+}
+
+// Create the scene and camera copies used by each thread.
+func CreateThreadCopies(scene *geometry.Scene, camera camera.Camera) {
 	if Multithreaded && MultithreadDataCopies {
 		for i := 0; i < MultithreadedTheads; i++ {
 			SceneCopies = append(SceneCopies, scene.Clone())
 			CameraCopies = append(CameraCopies, camera.Clone())
 		}
 	}
+}
 
-	var config = pixelgl.WindowConfig{
-		Resizable: false,
-		Undecorated: false,
-		VSync: false,
-		Title: "Gotracer",
-		Bounds: windowBounds}
-
-	var window, err = pixelgl.NewWindow(config)
-
-	CheckError(err)
-
-	var delta time.Duration
-
-	for !window.Closed() {
-		
-		var start = time.Now()
-
-		window.Clear(colornames.Black)
-
-		var picture *pixel.PictureData = Render(bounds, scene, camera)
-		var sprite *pixel.Sprite
-
-		if TemporalFilter {
-
-			// Add new frame to the list
-			Frames = append(Frames, picture)
-			if len(Frames) > TemporalFilterSamples {
-				Frames = Frames[1:]
-			}
-
-			var final = pixel.MakePictureData(bounds)
-
-			// Average the Frames in the list
-			for i := 0; i < len(final.Pix); i++ {
-
-				var r, g, b int
+// Render the scene offline without opening a window.
+// Multiple jittered samples are accumulated in the film, the result is written to the output file.
+func RunHeadless(fname string, samples int) {
+	var bounds = pixel.R(0, 0, Width, Height)
 
-				for j := 0; j < len(Frames); j++ {
-					r += (int)(Frames[j].Pix[i].R)
-					g += (int)(Frames[j].Pix[i].G)
-					b += (int)(Frames[j].Pix[i].B)
-				}
+	var scene, camera = LoadScene(bounds)
 
-				final.Pix[i].R = (uint8)(r / len(Frames))
-				final.Pix[i].G = (uint8)(g / len(Frames))
-				final.Pix[i].B = (uint8)(b / len(Frames))
-			}
+	CreateThreadCopies(scene, camera)
 
-			sprite = pixel.NewSprite(final, final.Bounds())
-		} else {
-			sprite = pixel.NewSprite(picture, picture.Bounds())
-		}
-		sprite.Draw(window, pixel.IM.Moved(window.Bounds().Center()).Scaled(window.Bounds().Center(), Upscale))
+	if samples < 1 {
+		samples = 1
+	}
 
-		delta = time.Since(start)
-		log.Printf("Frame time %s", delta)
+	var frame = film.NewFilm(int(Width), int(Height))
 
-		var speed = 1.0 * delta.Seconds()
+	for s := 0; s < samples; s++ {
+		var start = time.Now()
+		Render(frame, scene, camera)
+		log.Printf("Sample %d/%d time %s", s + 1, samples, time.Since(start))
+	}
 
-		//Keyboard input
-		if window.Pressed(pixelgl.KeyRight) {
-			camera.Position.X += speed
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeyLeft) {
-			camera.Position.X -= speed
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeyUp) {
-			camera.Position.Z -= speed
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeyDown) {
-			camera.Position.Z += speed
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeyLeftControl) || window.Pressed(pixelgl.KeyRightControl) {
-			camera.Position.Y -= speed
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeySpace) {
-			camera.Position.Y += speed
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeyW) {
-			camera.Aperture += 0.1
-			UpdateCamera(camera)
-		}
-		if window.Pressed(pixelgl.KeyS) {
-			camera.Aperture -= 0.1
-			UpdateCamera(camera)
-		}
+	var options = output.NewOptions()
+	options.Quality = *Quality
+	options.Depth16 = *Depth16
+	options.ToneMapper = ToneMapper
 
-		window.Update()
-	}
+	CheckError(output.WriteFilm(fname, frame, options))
 }
 
 // Update the camera viewport
-func UpdateCamera(camera *camera.CameraDefocus){
+// The samples accumulated in the film are discarded since they were rendered from the old viewport.
+func UpdateCamera(camera camera.Camera, frame *film.Film){
 
 	if TemporalFilter {
-		Frames = nil
+		frame.Clear()
 	}
 
 	if Multithreaded && MultithreadDataCopies{
 		for i := 0; i < MultithreadedTheads; i++ {
-			CameraCopies[i].Copy(camera)
-			CameraCopies[i].UpdateViewport()
+			CameraCopies[i] = camera.Clone()
 		}
 	} else {
 		camera.UpdateViewport()
 	}
 }
 
-//Render image the image
+//Render one sample per pixel of the scene into the film.
 //go:norace
-func Render(bounds pixel.Rect, scene *geometry.Scene, camera *camera.CameraDefocus) *pixel.PictureData {
-	var size = bounds.Size()
-	var picture *pixel.PictureData = pixel.MakePictureData(bounds)
-	var nx = int(size.X)
-	var ny = int(size.Y)
+func Render(frame *film.Film, scene *geometry.Scene, camera camera.Camera) {
+	var nx = frame.Width
+	var ny = frame.Height
+	var width = float64(nx)
+	var height = float64(ny)
 	var wg sync.WaitGroup
 
 	if Multithreaded {
@@ -257,32 +257,35 @@ func Render(bounds pixel.Rect, scene *geometry.Scene, camera *camera.CameraDefoc
 		var wtx = nx / MultithreadedTheads
 		var itx = 0
 
-		if MultithreadDataCopies {
-			for i := 0; i < MultithreadedTheads; i++ {
-				go RaytraceThread(&wg, picture, SceneCopies[i], CameraCopies[i], MaxDepth, TemporalFilter, Antialiasing, size.X, size.Y, itx, 0, itx + wtx, ny)
-				itx += wtx
+		for i := 0; i < MultithreadedTheads; i++ {
+			// Last thread also renders the remaining columns
+			var end = itx + wtx
+			if i == MultithreadedTheads - 1 {
+				end = nx
 			}
-		} else {
-			for i := 0; i < MultithreadedTheads; i++ {
-				go RaytraceThread(&wg, picture, scene, camera, MaxDepth, TemporalFilter, Antialiasing, size.X, size.Y, itx, 0, itx + wtx, ny)
-				itx += wtx
+
+			if MultithreadDataCopies {
+				go RaytraceThread(&wg, frame, SceneCopies[i], CameraCopies[i], MaxDepth, TemporalFilter, Antialiasing, width, height, itx, 0, end, ny)
+			} else {
+				go RaytraceThread(&wg, frame, scene, camera, MaxDepth, TemporalFilter, Antialiasing, width, height, itx, 0, end, ny)
 			}
+			itx += wtx
 		}
 
 		wg.Wait()
 	} else {
 		wg.Add(1)
-		RaytraceThread(&wg, picture, scene, camera, MaxDepth, TemporalFilter, Antialiasing, size.X, size.Y, 0, 0, nx, ny)
+		RaytraceThread(&wg, frame, scene, camera, MaxDepth, TemporalFilter, Antialiasing, width, height, 0, 0, nx, ny)
 	}
 
-	return picture
+	frame.Samples++
 }
 
 // Ray trace the picture in a thread and write it to the output object.
-// The result is written to the picture object passed as argument.
+// The linear radiance sample of each pixel is added to the film passed as argument.
 // This method is intended to be called multiple threads.
 //go:norace
-func RaytraceThread(wg *sync.WaitGroup, picture *pixel.PictureData, scene *geometry.Scene, camera *camera.CameraDefocus, depth int64, jitter bool, antialiasing bool, width float64, height float64, ix int, iy int, nx int, ny int) {
+func RaytraceThread(wg *sync.WaitGroup, frame *film.Film, scene *geometry.Scene, camera camera.Camera, depth int64, jitter bool, antialiasing bool, width float64, height float64, ix int, iy int, nx int, ny int) {
 	for j := iy; j < ny; j++ {
 		for i := ix; i < nx; i++ {
 			var color *vmath.Vector3
@@ -295,7 +298,7 @@ func RaytraceThread(wg *sync.WaitGroup, picture *pixel.PictureData, scene *geome
 				for k := 0; k < samples; k++ {
 					var u = (float64(i) + rand.Float64()) / width
 					var v = (float64(j) + rand.Float64()) / height
-					color.Add(RaytraceScene(scene, camera.GetRay(u, v), depth))
+					color.Add(RaytraceCamera(scene, camera, u, v, depth))
 				}
 
 				color.DivideScalar(float64(samples))
@@ -311,56 +314,126 @@ func RaytraceThread(wg *sync.WaitGroup, picture *pixel.PictureData, scene *geome
 					v = float64(j) / height
 				}
 
-				color = RaytraceScene(scene, camera.GetRay(u, v), depth)
+				color = RaytraceCamera(scene, camera, u, v, depth)
 			}
 
-			//Apply gamma
-			//color.DivideScalar(1.0);
-			color.Sqrt()
-
-			color.MulScalar(255)
-
-			//Write to picture
-			var index = picture.Index(pixel.Vec{X:float64(i), Y:float64(j)})
-			picture.Pix[index].R = uint8(color.X)
-			picture.Pix[index].G = uint8(color.Y)
-			picture.Pix[index].B = uint8(color.Z)
+			//Write to film
+			frame.AddSample(i, j, color)
 		}
 	}
 
 	wg.Done()
 }
 
+// Render the scene for the camera ray of a normalized UV screen coordinate.
+// Coordinates without a camera ray (outside of the fisheye image circle or blocked by the lens barrel) are black.
+//go:norace
+func RaytraceCamera(scene *geometry.Scene, camera camera.Camera, u float64, v float64, depth int64) *vmath.Vector3 {
+	var ray = camera.GetRay(u, v)
+	if ray == nil {
+		return vmath.NewEmptyVector3()
+	}
+
+	return RaytraceScene(scene, ray, depth, 0)
+}
+
 // Render the scene to calculate the color for a ray.
 // Receives the scene and the initial ray to be casted.
 // It is called recursively until the ray does not hit anything, it is absorbed of depth reaches 0.
+// Light sampling and BSDF sampling are combined using multiple importance sampling.
+// The bsdfPDF is the density of the material sampling the ray in the previous bounce, zero if it cannot be combined (camera rays and specular materials).
 //go:norace
-func RaytraceScene(scene *geometry.Scene, ray *vmath.Ray, depth int64) *vmath.Vector3 {
+func RaytraceScene(scene *geometry.Scene, ray *vmath.Ray, depth int64, bsdfPDF float64) *vmath.Vector3 {
 	var hitRecord = material.NewHitRecord()
 
 	if scene.Hit(ray, MinDistance, math.MaxFloat64, hitRecord) {
 
+		var color = hitRecord.Material.Emitted(ray, hitRecord)
+
+		// Weight the light found by BSDF sampling against the light sampling strategy
+		if bsdfPDF > 0 && color.SquaredLength() > 0 {
+			color.MulScalar(MISWeight(bsdfPDF, scene.LightPDF(ray.Origin, ray.Direction, ray.Time)))
+		}
+
 		var scattered = vmath.NewEmptyRay()
 		var attenuation = vmath.NewVector3(0, 0, 0)
 
+		if depth > 0 {
+			// Light sampling is independent of the scattered ray, it is also added when the BSDF sample is absorbed
+			color.Add(SampleLights(scene, ray, hitRecord))
+		}
+
 		if depth > 0 && hitRecord.Material.Scatter(ray, hitRecord, attenuation, scattered) {
-			var color = attenuation.Clone()
-			color.Mul(RaytraceScene(scene, scattered.Clone(), depth - 1))
-			return color
-		} else {
-			// Ray was absorved return black
-			//return vmath.NewVector3(0, 0, 0);
-
-			// The ray was absorved use the last value
-			return attenuation.Clone()
+			var pdf = hitRecord.Material.PDF(ray, hitRecord, scattered.Direction)
+
+			attenuation.Mul(RaytraceScene(scene, scattered.Clone(), depth - 1, pdf))
+			color.Add(attenuation)
 		}
 
+		// If the ray was absorbed only the emitted light is returned
+		return color
+
 	} else {
 
 		return BackgroundColor(ray)
 	}
 }
 
+// Sample the light arriving directly from the scene lights to the hit point (next event estimation).
+// A shadow ray is casted towards a random point of a random light, the light is only counted if the ray reaches it.
+// Volumes do not block the shadow ray, the light is attenuated by their transmittance.
+// The result is weighted against the BSDF sampling strategy, specular materials cannot be light sampled and return black.
+//go:norace
+func SampleLights(scene *geometry.Scene, ray *vmath.Ray, hitRecord *material.HitRecord) *vmath.Vector3 {
+	var direction = scene.SampleLight(hitRecord.P, ray.Time)
+	if direction == nil {
+		return vmath.NewEmptyVector3()
+	}
+
+	var color = hitRecord.Material.Evaluate(ray, hitRecord, direction)
+	if color == nil || color.SquaredLength() == 0 {
+		return vmath.NewEmptyVector3()
+	}
+
+	var shadow = vmath.NewRayTime(hitRecord.P.Clone(), direction, ray.Time)
+	var shadowRecord = material.NewHitRecord()
+
+	if !scene.HitSurface(shadow, MinDistance, math.MaxFloat64, shadowRecord) {
+		return vmath.NewEmptyVector3()
+	}
+
+	// Light is attenuated by the volumes between the hit point and the light
+	var transmittance = scene.Transmittance(shadow, MinDistance, shadowRecord.T)
+	if transmittance <= 0 {
+		return vmath.NewEmptyVector3()
+	}
+
+	var lightPDF = scene.LightPDF(shadow.Origin, shadow.Direction, shadow.Time)
+	if lightPDF <= 0 {
+		return vmath.NewEmptyVector3()
+	}
+
+	var bsdfPDF = hitRecord.Material.PDF(ray, hitRecord, direction)
+
+	color.Mul(shadowRecord.Material.Emitted(shadow, shadowRecord))
+	color.MulScalar(transmittance * MISWeight(lightPDF, bsdfPDF) / lightPDF)
+	return color
+}
+
+// Multiple importance sampling weight of a sample from a strategy with density pdf, combined with another strategy.
+// Uses the power heuristic with the MISPower exponent.
+//go:norace
+func MISWeight(pdf float64, other float64) float64 {
+	var a = math.Pow(pdf, MISPower)
+	var b = math.Pow(other, MISPower)
+
+	if a + b == 0 {
+		return 0.0
+	}
+
+	return a / (a + b)
+}
+
 // Calculate the background color from ray.
 // This method is used for multi threading.
 //go:norace
@@ -379,23 +452,24 @@ func BackgroundColor(r *vmath.Ray) *vmath.Vector3 {
 	return a
 }
 
-// Load obj file triangle into the scene.
+// Load obj file mesh into the scene.
+// The material is used for the faces without a material from the MTL files.
 //go:norace
-func LoadOBJ(scene *geometry.Scene, fname string, material material.Material) {
-	var file, _ = os.Open(fname)
+func LoadOBJ(scene *geometry.Scene, fname string, material material.Material) error {
+	var object, err = wavefront.Load(fname)
+	if err != nil {
+		return err
+	}
 
-	var data, _ = ioutil.ReadAll(file)
+	var mesh *geometry.Mesh
+	mesh, err = object.Mesh(material)
+	if err != nil {
+		return err
+	}
 
-	var reader = obj.NewReader(bytes.NewBuffer(data))
-	var object, _ = reader.Read()
+	scene.Add(mesh)
 
-	for i := 0; i < len(object.Faces); i++ {
-		var points = object.Faces[i].Points
-		var a = vmath.NewVector3(points[0].Vertex.X, points[0].Vertex.Y, points[0].Vertex.Z)
-		var b = vmath.NewVector3(points[1].Vertex.X, points[1].Vertex.Y, points[1].Vertex.Z)
-		var c = vmath.NewVector3(points[2].Vertex.X, points[2].Vertex.Y, points[2].Vertex.Z)
-		scene.Add(geometry.NewTriangle(a, b, c, material))
-	}
+	return nil
 }
 
 // Write the frame to a PPM file string.
@@ -411,7 +485,8 @@ func WritePPM(picture *pixel.PictureData, fname string) {
 
 	_, _ = file.WriteString("P3\n" + strconv.Itoa(nx) + " " + strconv.Itoa(ny) + "\n255\n")
 
-	for j := 0; j < ny; j++ {
+	// PPM rows are written from the top, the picture origin is at the bottom
+	for j := ny - 1; j >= 0; j-- {
 		for i := 0; i < nx; i++ {
 			//Write to file
 			var index = picture.Index(pixel.Vec{X:float64(i), Y:float64(j)})
@@ -0,0 +1,15 @@
+package material
+
+import (
+	"gotracer/texture"
+	"gotracer/vmath"
+)
+
+// Get the albedo of a material at the hit point.
+// If the material has a texture the color is sampled from the texture, otherwise the albedo color is used.
+func albedoAt(albedo *vmath.Vector3, tex texture.Texture, hitRecord *HitRecord) *vmath.Vector3 {
+	if tex != nil {
+		return tex.Value(hitRecord.U, hitRecord.V, hitRecord.P)
+	}
+	return albedo.Clone()
+}
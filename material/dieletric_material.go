@@ -1,7 +1,9 @@
 package material
 
 import (
+	"gotracer/texture"
 	"gotracer/vmath"
+	"math"
 	"math/rand"
 )
 
@@ -15,6 +17,9 @@ type DieletricMaterial struct {
 
 	// Albedo represents the color of the material.
 	Albedo *vmath.Vector3
+
+	// Texture used as albedo, if set it is used instead of the albedo color.
+	Texture texture.Texture
 }
 
 func NewDieletricMaterial (refractiveIndice float64, albedo *vmath.Vector3) *DieletricMaterial  {
@@ -24,6 +29,15 @@ func NewDieletricMaterial (refractiveIndice float64, albedo *vmath.Vector3) *Die
 	return m
 }
 
+// Create new dielectric material with a texture as albedo.
+func NewDieletricMaterialTexture(refractiveIndice float64, tex texture.Texture) *DieletricMaterial {
+	var m = new(DieletricMaterial)
+	m.RefractiveIndice = refractiveIndice
+	m.Albedo = vmath.NewVector3(1.0, 1.0, 1.0)
+	m.Texture = tex
+	return m
+}
+
 // Refractive indice of the air is 1.0
 var AirRefractiveIndice = 1.0
 
@@ -37,11 +51,14 @@ func (m *DieletricMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenu
 	var cosine float64
 
 	//attenuation.Set(1.0, 1.0, 1.0);
-	attenuation.Copy(m.Albedo)
+	attenuation.Copy(albedoAt(m.Albedo, m.Texture, hitRecord))
+	scattered.Time = ray.Time
 
-	var dot = vmath.Dot(ray.Direction, hitRecord.Normal)
+	// Absolute value used because interpolated normals may not agree with the face that was hit
+	var dot = math.Abs(vmath.Dot(ray.Direction, hitRecord.Normal))
 
-	if dot > 0 {
+	// Leaving the object when the back face is hit
+	if !hitRecord.FrontFace {
 		outwardNormal.Copy(hitRecord.Normal)
 		outwardNormal.MulScalar(-1.0)
 		refractionRatio = m.RefractiveIndice
@@ -49,7 +66,7 @@ func (m *DieletricMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenu
 	} else {
 		outwardNormal.Copy(hitRecord.Normal)
 		refractionRatio = AirRefractiveIndice / m.RefractiveIndice
-		cosine = -dot / ray.Direction.Length()
+		cosine = dot / ray.Direction.Length()
 	}
 
 	if vmath.Refract(ray.Direction, outwardNormal, refractionRatio, refracted) {
@@ -71,9 +88,22 @@ func (m *DieletricMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenu
 	return true
 }
 
+func (m *DieletricMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return vmath.NewEmptyVector3()
+}
+
+func (m *DieletricMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	return nil
+}
+
+func (m *DieletricMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	return 0.0
+}
+
 func (o *DieletricMaterial) Clone() Material {
 	var m = new(DieletricMaterial)
 	m.Albedo = o.Albedo.Clone()
 	m.RefractiveIndice = o.RefractiveIndice
+	m.Texture = o.Texture
 	return m
 }
\ No newline at end of file
@@ -0,0 +1,85 @@
+package material
+
+import (
+	"gotracer/texture"
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+)
+
+// Henyey-Greenstein material is the phase function of participating media that scatter light preferably forward or backward.
+// Forward scattering media (haze, clouds) make light shafts visible when looking towards the light.
+type HenyeyGreensteinMaterial struct {
+	Albedo *vmath.Vector3
+
+	// Texture used as albedo, if set it is used instead of the albedo color.
+	Texture texture.Texture
+
+	// Asymmetry of the scattering between -1 and 1, positive values scatter forward, negative values backward and zero is isotropic.
+	G float64
+}
+
+func NewHenyeyGreensteinMaterial(albedo *vmath.Vector3, g float64) *HenyeyGreensteinMaterial {
+	var m = new(HenyeyGreensteinMaterial)
+	m.Albedo = albedo
+	m.G = g
+	return m
+}
+
+// Create new Henyey-Greenstein material with a texture as albedo.
+func NewHenyeyGreensteinMaterialTexture(texture texture.Texture, g float64) *HenyeyGreensteinMaterial {
+	var m = new(HenyeyGreensteinMaterial)
+	m.Albedo = vmath.NewVector3(1.0, 1.0, 1.0)
+	m.Texture = texture
+	m.G = g
+	return m
+}
+
+// The angle to the ray direction is sampled from the phase function, the rotation around the ray is uniform.
+func (m *HenyeyGreensteinMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool {
+	var cosine float64
+	if math.Abs(m.G) < 1e-3 {
+		cosine = 1.0 - 2.0 * rand.Float64()
+	} else {
+		var s = (1.0 - m.G * m.G) / (1.0 - m.G + 2.0 * m.G * rand.Float64())
+		cosine = (1.0 + m.G * m.G - s * s) / (2.0 * m.G)
+	}
+
+	var sine = math.Sqrt(math.Max(0.0, 1.0 - cosine * cosine))
+	var phi = 2.0 * math.Pi * rand.Float64()
+
+	scattered.Set(hitRecord.P, vmath.NewONB(ray.Direction).Local(sine * math.Cos(phi), sine * math.Sin(phi), cosine))
+	scattered.Time = ray.Time
+	attenuation.Copy(albedoAt(m.Albedo, m.Texture, hitRecord))
+	return true
+}
+
+func (m *HenyeyGreensteinMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return vmath.NewEmptyVector3()
+}
+
+// Volumes have no surface, the phase function is used without the cosine term.
+func (m *HenyeyGreensteinMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	var color = albedoAt(m.Albedo, m.Texture, hitRecord)
+	color.MulScalar(m.PDF(ray, hitRecord, direction))
+	return color
+}
+
+func (m *HenyeyGreensteinMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	var cosine = vmath.Dot(ray.Direction, direction) / (ray.Direction.Length() * direction.Length())
+	return HenyeyGreenstein(cosine, m.G)
+}
+
+// Henyey-Greenstein phase function, density of scattering with a angle to the ray direction.
+func HenyeyGreenstein(cosine float64, g float64) float64 {
+	var denominator = 1.0 + g * g - 2.0 * g * cosine
+	return (1.0 - g * g) / (4.0 * math.Pi * denominator * math.Sqrt(denominator))
+}
+
+func (o *HenyeyGreensteinMaterial) Clone() Material {
+	var m = new(HenyeyGreensteinMaterial)
+	m.Albedo = o.Albedo.Clone()
+	m.Texture = o.Texture
+	m.G = o.G
+	return m
+}
@@ -12,11 +12,18 @@ type HitRecord struct {
 	// Point of collision.
 	P *vmath.Vector3
 
-	// Normal of the surface where the ray collided.
+	// Normal of the surface where the ray collided, points outside of the object.
 	Normal *vmath.Vector3
+
+	// Indicates if the ray hit the front (outside) face of the surface.
+	FrontFace bool
 	
 	// Material in the surface where the ray collided.
 	Material Material
+
+	// Texture coordinates of the surface where the ray collided.
+	U float64
+	V float64
 }
 
 // Create new hitable list
@@ -33,5 +40,18 @@ func (a *HitRecord) Copy(b *HitRecord) {
 	a.T = b.T
 	a.P.Copy(b.P)
 	a.Normal.Copy(b.Normal)
+	a.FrontFace = b.FrontFace
 	a.Material = b.Material
+	a.U = b.U
+	a.V = b.V
 }
+
+// Normal on the side of the surface hit by the ray, opposite to the normal if the back face was hit.
+// Used by opaque materials that reflect light on the side of the surface that was hit.
+func (hr *HitRecord) FacingNormal() *vmath.Vector3 {
+	var normal = hr.Normal.Clone()
+	if !hr.FrontFace {
+		normal.MulScalar(-1.0)
+	}
+	return normal
+}
\ No newline at end of file
@@ -0,0 +1,60 @@
+package material
+
+import (
+	"gotracer/texture"
+	"gotracer/vmath"
+	"math"
+)
+
+// Isotropic material is the phase function of participating media that scatter light equally in all directions.
+// Used by volumes (fog, smoke), the albedo is the fraction of the light that is scattered instead of absorbed.
+type IsotropicMaterial struct {
+	Albedo *vmath.Vector3
+
+	// Texture used as albedo, if set it is used instead of the albedo color.
+	Texture texture.Texture
+}
+
+func NewIsotropicMaterial(albedo *vmath.Vector3) *IsotropicMaterial {
+	var m = new(IsotropicMaterial)
+	m.Albedo = albedo
+	return m
+}
+
+// Create new isotropic material with a texture as albedo.
+func NewIsotropicMaterialTexture(texture texture.Texture) *IsotropicMaterial {
+	var m = new(IsotropicMaterial)
+	m.Albedo = vmath.NewVector3(1.0, 1.0, 1.0)
+	m.Texture = texture
+	return m
+}
+
+// The scattered direction is uniformly distributed over the sphere.
+func (m *IsotropicMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool {
+	scattered.Set(hitRecord.P, vmath.RandomUnitVector())
+	scattered.Time = ray.Time
+	attenuation.Copy(albedoAt(m.Albedo, m.Texture, hitRecord))
+	return true
+}
+
+func (m *IsotropicMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return vmath.NewEmptyVector3()
+}
+
+// Volumes have no surface, the phase function is used without the cosine term.
+func (m *IsotropicMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	var color = albedoAt(m.Albedo, m.Texture, hitRecord)
+	color.MulScalar(1.0 / (4.0 * math.Pi))
+	return color
+}
+
+func (m *IsotropicMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	return 1.0 / (4.0 * math.Pi)
+}
+
+func (o *IsotropicMaterial) Clone() Material {
+	var m = new(IsotropicMaterial)
+	m.Albedo = o.Albedo.Clone()
+	m.Texture = o.Texture
+	return m
+}
@@ -1,7 +1,9 @@
 package material
 
 import (
+	"gotracer/texture"
 	"gotracer/vmath"
+	"math"
 )
 
 // Lambert material materials are diffuse objects that don’t emit light merely take on the color of their surroundings.
@@ -10,6 +12,9 @@ import (
 type LambertMaterial struct {
 	// Albedo represents the base color of the material.
 	Albedo *vmath.Vector3
+
+	// Texture used as albedo, if set it is used instead of the albedo color.
+	Texture texture.Texture
 }
 
 func NewLambertMaterial(albedo *vmath.Vector3) *LambertMaterial {
@@ -18,18 +23,61 @@ func NewLambertMaterial(albedo *vmath.Vector3) *LambertMaterial {
 	return m
 }
 
+// Create new lambert material with a texture as albedo.
+func NewLambertMaterialTexture(texture texture.Texture) *LambertMaterial {
+	var m = new(LambertMaterial)
+	m.Albedo = vmath.NewVector3(1.0, 1.0, 1.0)
+	m.Texture = texture
+	return m
+}
+
+// The scattered direction follows a cosine distribution around the normal, the attenuation is the albedo.
 func (m *LambertMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool {
-	var direction = hitRecord.Normal.Clone()
-	direction.Add(vmath.RandomInUnitSphere())
+	var normal = hitRecord.FacingNormal()
+	var direction = normal.Clone()
+	direction.Add(vmath.RandomUnitVector())
+
+	// Avoid degenerate directions when the random vector is opposite to the normal
+	if direction.SquaredLength() < 1e-12 {
+		direction.Copy(normal)
+	}
 
 	scattered.Set(hitRecord.P, direction)
-	attenuation.Copy(m.Albedo)
+	scattered.Time = ray.Time
+	attenuation.Copy(albedoAt(m.Albedo, m.Texture, hitRecord))
 
 	return true
 }
 
+func (m *LambertMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return vmath.NewEmptyVector3()
+}
+
+func (m *LambertMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	return Lambert(albedoAt(m.Albedo, m.Texture, hitRecord), hitRecord.FacingNormal(), direction)
+}
+
+func (m *LambertMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	return CosinePDF(hitRecord.FacingNormal(), direction)
+}
+
+// Probability density of a cosine distribution around the normal (cosine / pi).
+func CosinePDF(normal *vmath.Vector3, direction *vmath.Vector3) float64 {
+	var cosine = vmath.Dot(normal, direction) / direction.Length()
+	return math.Max(cosine, 0.0) / math.Pi
+}
+
+// Diffuse reflection (albedo / pi) multiplied by the cosine between the normal and the direction.
+func Lambert(albedo *vmath.Vector3, normal *vmath.Vector3, direction *vmath.Vector3) *vmath.Vector3 {
+	var cosine = vmath.Dot(normal, direction) / direction.Length()
+	var color = albedo.Clone()
+	color.MulScalar(math.Max(cosine, 0.0) / math.Pi)
+	return color
+}
+
 func (o *LambertMaterial) Clone() Material {
 	var m = new(LambertMaterial)
 	m.Albedo = o.Albedo.Clone()
+	m.Texture = o.Texture
 	return m
 }
\ No newline at end of file
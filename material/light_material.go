@@ -5,8 +5,9 @@ import (
 )
 
 // Light material emits light. The color of the object is the solid color of the light.
+// Light does not scatter rays, objects with this material are used as light sources for direct light sampling.
 type LightMaterial struct {
-	// Color of the light.
+	// Color of the light, values above 1.0 can be used for brighter lights.
 	Color *vmath.Vector3
 }
 
@@ -17,11 +18,25 @@ func NewLightMaterial(color *vmath.Vector3) *LightMaterial {
 }
 
 func (m *LightMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool {
+	return false
+}
+
+func (m *LightMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return m.Color.Clone()
+}
 
-	scattered.Set(hitRecord.P, hitRecord.Normal.Clone())
-	attenuation.Add(m.Color)
+func (m *LightMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	return nil
+}
+
+func (m *LightMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	return 0.0
+}
 
-	return true
+// Check if a material emits light.
+func IsEmissive(m Material) bool {
+	var _, ok = m.(*LightMaterial)
+	return ok
 }
 
 func (o *LightMaterial) Clone() Material {
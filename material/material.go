@@ -12,6 +12,17 @@ type Material interface {
 	// The return value indicates if if the ray was scatered, if returned false we assume that the ray was absorved.
 	Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool
 
+	// Light emitted by the surface at the hit point, materials that do not emit light return black.
+	Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3
+
+	// Evaluate the light reflected from a direction into the ray, the BSDF multiplied by the cosine of the direction.
+	// Used for direct light sampling, materials that cannot be sampled this way (specular materials) return nil.
+	Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3
+
+	// Probability density (relative to solid angle) of Scatter producing a direction.
+	// Materials that scatter in a single direction (specular materials) return zero.
+	PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64
+
 	// Clone object create a new object with the same properties.
 	Clone() Material
 }
\ No newline at end of file
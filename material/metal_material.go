@@ -1,7 +1,9 @@
 package material
 
 import (
+	"gotracer/texture"
 	"gotracer/vmath"
+	"math"
 )
 
 // Metalic object type reflect the rays that hit the object surface.
@@ -9,6 +11,9 @@ type MetalMaterial struct {
 	// Albedo represents the base color of the material.
 	Albedo *vmath.Vector3
 
+	// Texture used as albedo, if set it is used instead of the albedo color.
+	Texture texture.Texture
+
 	// Fuzz indicates the roughness of the metallic surface.
 	// The more fuzz there is the more the ray are reflected with an offset applied.
 	Fuzz float64
@@ -21,10 +26,20 @@ func NewMetalMaterial(albedo *vmath.Vector3, fuzz float64) *MetalMaterial {
 	return m
 }
 
+// Create new metal material with a texture as albedo.
+func NewMetalMaterialTexture(texture texture.Texture, fuzz float64) *MetalMaterial {
+	var m = new(MetalMaterial)
+	m.Albedo = vmath.NewVector3(1.0, 1.0, 1.0)
+	m.Texture = texture
+	m.Fuzz = fuzz
+	return m
+}
+
 func (m *MetalMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool {
 
+	var normal = hitRecord.FacingNormal()
 	var unit = ray.Direction.UnitVector()
-	var reflected = vmath.Reflect(unit, hitRecord.Normal)
+	var reflected = vmath.Reflect(unit, normal)
 
 	if m.Fuzz != 0 {
 		var fuzzOffset = vmath.RandomInUnitSphere()
@@ -33,14 +48,64 @@ func (m *MetalMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuatio
 	}
 
 	scattered.Set(hitRecord.P, reflected)
-	attenuation.Copy(m.Albedo)
+	scattered.Time = ray.Time
+	attenuation.Copy(albedoAt(m.Albedo, m.Texture, hitRecord))
+
+	return vmath.Dot(scattered.Direction, normal) > 0
+}
+
+func (m *MetalMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return vmath.NewEmptyVector3()
+}
+
+// Rough metals reflect the albedo weighted by the density of the fuzzy reflection, perfect mirrors cannot be evaluated.
+func (m *MetalMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	if m.Fuzz == 0 {
+		return nil
+	}
+
+	var color = albedoAt(m.Albedo, m.Texture, hitRecord)
+
+	if vmath.Dot(direction, hitRecord.FacingNormal()) <= 0 {
+		color.Set(0, 0, 0)
+	} else {
+		color.MulScalar(m.PDF(ray, hitRecord, direction))
+	}
+
+	return color
+}
+
+// Scattered directions are uniformly distributed in a sphere with radius fuzz around the reflected direction.
+// The density of a direction is the volume of that sphere crossed by the line along the direction.
+func (m *MetalMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	var normal = hitRecord.FacingNormal()
+
+	if m.Fuzz == 0 || vmath.Dot(direction, normal) <= 0 {
+		return 0.0
+	}
+
+	var reflected = vmath.Reflect(ray.Direction.UnitVector(), normal)
+	var b = vmath.Dot(direction, reflected) / direction.Length()
+	var discriminant = b * b - 1.0 + m.Fuzz * m.Fuzz
+
+	if discriminant < 0 {
+		return 0.0
+	}
+
+	var t1 = math.Max(b - math.Sqrt(discriminant), 0.0)
+	var t2 = b + math.Sqrt(discriminant)
+
+	if t2 <= 0 {
+		return 0.0
+	}
 
-	return vmath.Dot(scattered.Direction, hitRecord.Normal) > 0
+	return (t2 * t2 * t2 - t1 * t1 * t1) / (4.0 * math.Pi * m.Fuzz * m.Fuzz * m.Fuzz)
 }
 
 func (o *MetalMaterial) Clone() Material {
 	var m = new(MetalMaterial)
 	m.Albedo = o.Albedo.Clone()
+	m.Texture = o.Texture
 	m.Fuzz = o.Fuzz
 	return m
 }
\ No newline at end of file
@@ -13,18 +13,40 @@ func NewNormalMaterial() *NormalMaterial {
 
 func (m *NormalMaterial) Scatter(ray *vmath.Ray, hitRecord *HitRecord, attenuation *vmath.Vector3, scattered *vmath.Ray) bool {
 
-	var target = hitRecord.Normal.Clone()
-	target.Add(vmath.RandomInUnitSphere())
+	var normal = hitRecord.FacingNormal()
+	var target = normal.Clone()
+	target.Add(vmath.RandomUnitVector())
 
-	var color = vmath.NewVector3(hitRecord.Normal.X + 1.0, hitRecord.Normal.Y + 1.0, hitRecord.Normal.Z + 1.0)
-	color.MulScalar(0.5)
+	if target.SquaredLength() < 1e-12 {
+		target.Copy(normal)
+	}
 
 	scattered.Set(hitRecord.P, target)
-	attenuation.Copy(color)
+	scattered.Time = ray.Time
+	attenuation.Copy(m.color(hitRecord))
 
 	return true
 }
 
+func (m *NormalMaterial) Emitted(ray *vmath.Ray, hitRecord *HitRecord) *vmath.Vector3 {
+	return vmath.NewEmptyVector3()
+}
+
+func (m *NormalMaterial) Evaluate(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) *vmath.Vector3 {
+	return Lambert(m.color(hitRecord), hitRecord.FacingNormal(), direction)
+}
+
+func (m *NormalMaterial) PDF(ray *vmath.Ray, hitRecord *HitRecord, direction *vmath.Vector3) float64 {
+	return CosinePDF(hitRecord.FacingNormal(), direction)
+}
+
+// Color of the surface calculated from the normal direction.
+func (m *NormalMaterial) color(hitRecord *HitRecord) *vmath.Vector3 {
+	var color = vmath.NewVector3(hitRecord.Normal.X + 1.0, hitRecord.Normal.Y + 1.0, hitRecord.Normal.Z + 1.0)
+	color.MulScalar(0.5)
+	return color
+}
+
 func (o *NormalMaterial) Clone() Material {
 	return new(NormalMaterial)
 }
\ No newline at end of file
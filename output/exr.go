@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"gotracer/film"
+	"io"
+	"math"
+)
+
+// Pixel type used to store 32 bit float channels in OpenEXR files.
+const exrPixelTypeFloat = 2
+
+// Encode the film radiance as a uncompressed scanline OpenEXR image with 32 bit float channels.
+func WriteEXR(w io.Writer, f *film.Film) error {
+	var header = new(bytes.Buffer)
+
+	// Magic number and version (single part scanline file)
+	_ = binary.Write(header, binary.LittleEndian, uint32(20000630))
+	_ = binary.Write(header, binary.LittleEndian, uint32(2))
+
+	// Channels are stored in alphabetical order
+	var channels = []string{"B", "G", "R"}
+	var list = new(bytes.Buffer)
+	for i := 0; i < len(channels); i++ {
+		list.WriteString(channels[i])
+		list.WriteByte(0)
+		_ = binary.Write(list, binary.LittleEndian, int32(exrPixelTypeFloat))
+		list.Write([]byte{0, 0, 0, 0})
+		_ = binary.Write(list, binary.LittleEndian, int32(1))
+		_ = binary.Write(list, binary.LittleEndian, int32(1))
+	}
+	list.WriteByte(0)
+
+	var window = new(bytes.Buffer)
+	_ = binary.Write(window, binary.LittleEndian, []int32{0, 0, int32(f.Width - 1), int32(f.Height - 1)})
+
+	var one = new(bytes.Buffer)
+	_ = binary.Write(one, binary.LittleEndian, float32(1.0))
+
+	writeEXRAttribute(header, "channels", "chlist", list.Bytes())
+	writeEXRAttribute(header, "compression", "compression", []byte{0})
+	writeEXRAttribute(header, "dataWindow", "box2i", window.Bytes())
+	writeEXRAttribute(header, "displayWindow", "box2i", window.Bytes())
+	writeEXRAttribute(header, "lineOrder", "lineOrder", []byte{0})
+	writeEXRAttribute(header, "pixelAspectRatio", "float", one.Bytes())
+	writeEXRAttribute(header, "screenWindowCenter", "v2f", make([]byte, 8))
+	writeEXRAttribute(header, "screenWindowWidth", "float", one.Bytes())
+	header.WriteByte(0)
+
+	// Offset table, each scanline is stored in its own chunk
+	var lineSize = f.Width * 4 * len(channels)
+	var chunkSize = 8 + lineSize
+	var offset = uint64(header.Len() + f.Height * 8)
+
+	for y := 0; y < f.Height; y++ {
+		_ = binary.Write(header, binary.LittleEndian, offset)
+		offset += uint64(chunkSize)
+	}
+
+	var _, err = w.Write(header.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Scanlines are stored from the top of the image
+	var chunk = make([]byte, chunkSize)
+
+	for line := 0; line < f.Height; line++ {
+		var y = f.Height - 1 - line
+
+		binary.LittleEndian.PutUint32(chunk[0:], uint32(line))
+		binary.LittleEndian.PutUint32(chunk[4:], uint32(lineSize))
+
+		for x := 0; x < f.Width; x++ {
+			var c = f.Color(x, y)
+			binary.LittleEndian.PutUint32(chunk[8 + x * 4:], math.Float32bits(float32(c.Z)))
+			binary.LittleEndian.PutUint32(chunk[8 + (f.Width + x) * 4:], math.Float32bits(float32(c.Y)))
+			binary.LittleEndian.PutUint32(chunk[8 + (f.Width * 2 + x) * 4:], math.Float32bits(float32(c.X)))
+		}
+
+		if _, err = w.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Write a header attribute, composed by its name, type, size and value.
+func writeEXRAttribute(buffer *bytes.Buffer, name string, kind string, value []byte) {
+	buffer.WriteString(name)
+	buffer.WriteByte(0)
+	buffer.WriteString(kind)
+	buffer.WriteByte(0)
+	_ = binary.Write(buffer, binary.LittleEndian, int32(len(value)))
+	buffer.Write(value)
+}
@@ -0,0 +1,123 @@
+package output
+
+import (
+	"gotracer/film"
+	"gotracer/vmath"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Encode the film radiance as a Radiance HDR (RGBE) image.
+// Scanlines are compressed with the run length encoding used by the Radiance format when the width allows it.
+func WriteHDR(w io.Writer, f *film.Film) error {
+	var _, err = io.WriteString(w, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y " + strconv.Itoa(f.Height) + " +X " + strconv.Itoa(f.Width) + "\n")
+	if err != nil {
+		return err
+	}
+
+	var scanline = make([]byte, f.Width * 4)
+	var compress = f.Width >= 8 && f.Width < 0x8000
+
+	// Rows are written from the top of the image
+	for y := f.Height - 1; y >= 0; y-- {
+		for x := 0; x < f.Width; x++ {
+			toRGBE(f.Color(x, y), scanline[x * 4:])
+		}
+
+		if compress {
+			err = writeRLEScanline(w, scanline, f.Width)
+		} else {
+			_, err = w.Write(scanline)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Convert a color into the shared exponent RGBE representation.
+func toRGBE(c *vmath.Vector3, rgbe []byte) {
+	var v = math.Max(c.X, math.Max(c.Y, c.Z))
+
+	if v < 1e-32 || math.IsNaN(v) {
+		rgbe[0], rgbe[1], rgbe[2], rgbe[3] = 0, 0, 0, 0
+		return
+	}
+
+	var mantissa, exponent = math.Frexp(v)
+	var scale = mantissa * 256.0 / v
+
+	rgbe[0] = byte(math.Max(c.X, 0) * scale)
+	rgbe[1] = byte(math.Max(c.Y, 0) * scale)
+	rgbe[2] = byte(math.Max(c.Z, 0) * scale)
+	rgbe[3] = byte(exponent + 128)
+}
+
+// Write a RGBE scanline with the new Radiance run length encoding.
+// Each component is stored separately, runs are stored as (128 + count, value) and literals as (count, values...).
+func writeRLEScanline(w io.Writer, scanline []byte, width int) error {
+	var buffer = []byte{2, 2, byte(width >> 8), byte(width & 0xff)}
+	var component = make([]byte, width)
+
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; x++ {
+			component[x] = scanline[x * 4 + c]
+		}
+		buffer = appendRLE(buffer, component)
+	}
+
+	var _, err = w.Write(buffer)
+	return err
+}
+
+// Minimum length of a run to be encoded as a run instead of literals.
+const minRunLength = 4
+
+// Append the run length encoding of data to the buffer.
+func appendRLE(buffer []byte, data []byte) []byte {
+	var current = 0
+
+	for current < len(data) {
+		// Find the next run long enough to be encoded
+		var runStart = current
+		var runLength = 0
+
+		for runStart < len(data) {
+			runLength = 1
+			for runStart + runLength < len(data) && runLength < 127 && data[runStart + runLength] == data[runStart] {
+				runLength++
+			}
+			if runLength >= minRunLength {
+				break
+			}
+			runStart += runLength
+		}
+
+		if runStart > len(data) {
+			runStart = len(data)
+		}
+
+		// Write the literal values before the run
+		for current < runStart {
+			var count = runStart - current
+			if count > 128 {
+				count = 128
+			}
+			buffer = append(buffer, byte(count))
+			buffer = append(buffer, data[current:current + count]...)
+			current += count
+		}
+
+		// Write the run
+		if runLength >= minRunLength && runStart < len(data) {
+			buffer = append(buffer, byte(128 + runLength), data[runStart])
+			current = runStart + runLength
+		}
+	}
+
+	return buffer
+}
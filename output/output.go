@@ -0,0 +1,192 @@
+package output
+
+import (
+	"bufio"
+	"errors"
+	"gotracer/film"
+	"gotracer/tonemap"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Default quality used to encode JPEG images.
+const DefaultQuality = 90
+
+// Options used to encode the image files.
+type Options struct {
+	// Quality of the JPEG images from 1 to 100.
+	Quality int
+
+	// If true PNG images are written with 16 bits per channel.
+	Depth16 bool
+
+	// Tone mapper used to convert the film radiance to display colors for low dynamic range formats.
+	ToneMapper *tonemap.ToneMapper
+}
+
+// Create new options with the default values.
+func NewOptions() *Options {
+	var o = new(Options)
+	o.Quality = DefaultQuality
+	o.Depth16 = false
+	o.ToneMapper = tonemap.NewToneMapper(tonemap.NewACESOperator())
+	return o
+}
+
+// Write a image to a file, the format is selected by the extension of the file name.
+// Supports PNG (.png), JPEG (.jpg, .jpeg) and binary PPM (.ppm).
+// The alpha channel is ignored, images are always written opaque.
+func Write(fname string, img image.Image, options *Options) error {
+	if options == nil {
+		options = NewOptions()
+	}
+
+	var extension = strings.ToLower(filepath.Ext(fname))
+	var encode func(w io.Writer) error
+
+	switch extension {
+	case ".png":
+		encode = func(w io.Writer) error { return WritePNG(w, img, options.Depth16) }
+	case ".jpg", ".jpeg":
+		encode = func(w io.Writer) error { return WriteJPEG(w, img, options.Quality) }
+	case ".ppm":
+		encode = func(w io.Writer) error { return WritePPM(w, img) }
+	default:
+		return errors.New("output: unsupported image format " + strconv.Quote(extension))
+	}
+
+	return writeFile(fname, encode)
+}
+
+// Write the film to a file, the format is selected by the extension of the file name.
+// High dynamic range formats PFM (.pfm), Radiance HDR (.hdr) and OpenEXR (.exr) store the linear radiance of the film.
+// Other formats store the display color of the film, converted using the tone mapper of the options.
+func WriteFilm(fname string, f *film.Film, options *Options) error {
+	if options == nil {
+		options = NewOptions()
+	}
+
+	var extension = strings.ToLower(filepath.Ext(fname))
+
+	switch extension {
+	case ".pfm":
+		return writeFile(fname, func(w io.Writer) error { return WritePFM(w, f) })
+	case ".hdr":
+		return writeFile(fname, func(w io.Writer) error { return WriteHDR(w, f) })
+	case ".exr":
+		return writeFile(fname, func(w io.Writer) error { return WriteEXR(w, f) })
+	}
+
+	return Write(fname, options.ToneMapper.Image(f), options)
+}
+
+// Create a file and write its content using a encode function.
+func writeFile(fname string, encode func(w io.Writer) error) error {
+	var file, err = os.Create(fname)
+	if err != nil {
+		return err
+	}
+
+	var writer = bufio.NewWriter(file)
+
+	err = encode(writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	var closeErr = file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// Encode a image as PNG with 8 or 16 bits per channel.
+func WritePNG(w io.Writer, img image.Image, depth16 bool) error {
+	if depth16 {
+		return png.Encode(w, ToRGBA64(img))
+	}
+	return png.Encode(w, ToRGBA(img))
+}
+
+// Encode a image as JPEG, quality ranges from 1 to 100.
+func WriteJPEG(w io.Writer, img image.Image, quality int) error {
+	if quality < 1 {
+		quality = 1
+	} else if quality > 100 {
+		quality = 100
+	}
+
+	var options = new(jpeg.Options)
+	options.Quality = quality
+
+	return jpeg.Encode(w, ToRGBA(img), options)
+}
+
+// Encode a image as binary PPM (P6) with 8 bits per channel.
+func WritePPM(w io.Writer, img image.Image) error {
+	var rgba = ToRGBA(img)
+	var bounds = rgba.Bounds()
+	var width = bounds.Dx()
+	var height = bounds.Dy()
+
+	var _, err = io.WriteString(w, "P6\n" + strconv.Itoa(width) + " " + strconv.Itoa(height) + "\n255\n")
+	if err != nil {
+		return err
+	}
+
+	var row = make([]byte, width * 3)
+
+	for y := 0; y < height; y++ {
+		var offset = y * rgba.Stride
+		for x := 0; x < width; x++ {
+			row[x * 3] = rgba.Pix[offset + x * 4]
+			row[x * 3 + 1] = rgba.Pix[offset + x * 4 + 1]
+			row[x * 3 + 2] = rgba.Pix[offset + x * 4 + 2]
+		}
+
+		if _, err = w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Convert a image to a opaque 8 bit RGBA image.
+func ToRGBA(img image.Image) *image.RGBA {
+	var bounds = img.Bounds()
+	var rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var r, g, b, _ = img.At(x, y).RGBA()
+			rgba.SetRGBA(x - bounds.Min.X, y - bounds.Min.Y, color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 255})
+		}
+	}
+
+	return rgba
+}
+
+// Convert a image to a opaque 16 bit RGBA image.
+func ToRGBA64(img image.Image) *image.RGBA64 {
+	var bounds = img.Bounds()
+	var rgba = image.NewRGBA64(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var r, g, b, _ = img.At(x, y).RGBA()
+			rgba.SetRGBA64(x - bounds.Min.X, y - bounds.Min.Y, color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: 0xffff})
+		}
+	}
+
+	return rgba
+}
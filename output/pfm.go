@@ -0,0 +1,36 @@
+package output
+
+import (
+	"encoding/binary"
+	"gotracer/film"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Encode the film radiance as a Portable Float Map (PFM) with 32 bit float per channel.
+// PFM stores rows from the bottom of the image, in the same order as the film.
+func WritePFM(w io.Writer, f *film.Film) error {
+	// Negative scale indicates little endian data
+	var _, err = io.WriteString(w, "PF\n" + strconv.Itoa(f.Width) + " " + strconv.Itoa(f.Height) + "\n-1.0\n")
+	if err != nil {
+		return err
+	}
+
+	var row = make([]byte, f.Width * 12)
+
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			var c = f.Color(x, y)
+			binary.LittleEndian.PutUint32(row[x * 12:], math.Float32bits(float32(c.X)))
+			binary.LittleEndian.PutUint32(row[x * 12 + 4:], math.Float32bits(float32(c.Y)))
+			binary.LittleEndian.PutUint32(row[x * 12 + 8:], math.Float32bits(float32(c.Z)))
+		}
+
+		if _, err = w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package scenefile
+
+import (
+	"encoding/json"
+)
+
+// Camera description, the type selects the projection ("perspective", "orthographic", "fisheye" or "panoramic").
+type cameraDescription struct {
+	Type string `json:"type"`
+	Position []float64 `json:"position"`
+	LookAt []float64 `json:"lookAt"`
+	Up []float64 `json:"up"`
+	Fov *float64 `json:"fov"`
+	Aperture float64 `json:"aperture"`
+
+	// If not specified the distance between the position and the look at point is used.
+	FocusDistance *float64 `json:"focusDistance"`
+
+	// If true the focus distance is the distance to the object at the center of the image.
+	Autofocus bool `json:"autofocus"`
+
+	// Shape of the aperture of perspective cameras, circular by default.
+	// Polygon with a number of blades rotated in degrees, or a grayscale image relative to the scene file.
+	Blades int `json:"blades"`
+	BladeRotation float64 `json:"bladeRotation"`
+	ApertureFile string `json:"apertureFile"`
+
+	// Anamorphic squeeze factor of the bokeh, 1 by default.
+	Squeeze *float64 `json:"squeeze"`
+
+	// Strength of the cat-eye vignetting, 0 by default.
+	CatEye float64 `json:"catEye"`
+
+	// Height of the area seen by orthographic cameras, if not specified it matches the perspective view at the look at point.
+	Height *float64 `json:"height"`
+
+	// Time when the shutter opens and closes, moving objects are blurred along the motion between them.
+	ShutterOpen float64 `json:"shutterOpen"`
+	ShutterClose float64 `json:"shutterClose"`
+
+	// If specified the camera is the center of a stereo rig.
+	Stereo json.RawMessage `json:"stereo"`
+}
+
+// Stereo rig description, renders the left and right eyes into the same image.
+type stereoDescription struct {
+	// Distance between the eyes, 0.064 by default.
+	Separation *float64 `json:"separation"`
+
+	// Convergence of the eyes, "offAxis" (default) or "toeIn".
+	Convergence string `json:"convergence"`
+
+	// If not specified the distance between the position and the look at point is used.
+	ConvergenceDistance *float64 `json:"convergenceDistance"`
+
+	// Placement of the eyes in the image, "sideBySide" (default) or "overUnder".
+	Layout string `json:"layout"`
+}
+
+// Material description, the fields used depend on the material type.
+type materialDescription struct {
+	Type string `json:"type"`
+	Albedo []float64 `json:"albedo"`
+	Color []float64 `json:"color"`
+	Fuzz float64 `json:"fuzz"`
+	RefractiveIndice *float64 `json:"refractiveIndice"`
+
+	// Asymmetry of the Henyey-Greenstein phase function, between -1 (backward) and 1 (forward).
+	G float64 `json:"g"`
+
+	// Texture used as albedo, if specified the albedo color is not required.
+	Texture json.RawMessage `json:"texture"`
+}
+
+// Texture description, the fields used depend on the texture type.
+type textureDescription struct {
+	Type string `json:"type"`
+	Color []float64 `json:"color"`
+
+	// Path of the image file, relative to the scene file.
+	File string `json:"file"`
+	Wrap string `json:"wrap"`
+	Filter string `json:"filter"`
+
+	// Checker cells, each one is a color or a nested texture.
+	Odd json.RawMessage `json:"odd"`
+	Even json.RawMessage `json:"even"`
+
+	// Space of the checker cells, "world" or "uv".
+	Space string `json:"space"`
+
+	// Frequency of the procedural pattern.
+	Scale *float64 `json:"scale"`
+
+	// Parameters of the noise based textures.
+	Seed int64 `json:"seed"`
+	Depth *int `json:"depth"`
+	Turbulence *float64 `json:"turbulence"`
+	Noise *float64 `json:"noise"`
+
+	// Colors of the two tone procedural textures.
+	ColorA []float64 `json:"colorA"`
+	ColorB []float64 `json:"colorB"`
+}
+
+// Fields shared by all objects, the type is used to select the description of the object.
+type objectType struct {
+	Type string `json:"type"`
+
+	// Name of the node of the object in the scene graph.
+	Name string `json:"name"`
+
+	// Disabled objects are not added to the scene, enabled by default.
+	Enabled *bool `json:"enabled"`
+
+	Transform json.RawMessage `json:"transform"`
+
+	// Transform of the object at the end of its movement, used for motion blur.
+	Motion json.RawMessage `json:"motion"`
+}
+
+// Group of objects, the transform of the group is applied to all children.
+type groupDescription struct {
+	objectType
+	Children json.RawMessage `json:"children"`
+}
+
+// Transform of a object, a matrix (16 values in row major order) or a combination of translation, rotation and scale.
+type transformDescription struct {
+	Matrix []float64 `json:"matrix"`
+	Translate []float64 `json:"translate"`
+
+	// Rotation in degrees around the X, Y and Z axis.
+	Rotate []float64 `json:"rotate"`
+
+	// Scale as a number or a list of 3 values.
+	Scale json.RawMessage `json:"scale"`
+}
+
+// Transform at the end of the movement of a object, components that are not specified do not change.
+type motionDescription struct {
+	Translate []float64 `json:"translate"`
+	Rotate []float64 `json:"rotate"`
+	Scale json.RawMessage `json:"scale"`
+
+	// Start and end time of the movement, [0, 1] by default.
+	Time []float64 `json:"time"`
+}
+
+type sphereDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Radius *float64 `json:"radius"`
+	Center []float64 `json:"center"`
+}
+
+type boxDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Min []float64 `json:"min"`
+	Max []float64 `json:"max"`
+}
+
+type triangleDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	A []float64 `json:"a"`
+	B []float64 `json:"b"`
+	C []float64 `json:"c"`
+
+	// Faces ignored by the ray intersection, "none", "back" or "front".
+	Cull string `json:"cull"`
+}
+
+type meshDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+
+	// Path of the mesh file, relative to the scene file.
+	File string `json:"file"`
+
+	// Name of the group (or object) of the file to load, all faces are loaded if empty.
+	Group string `json:"group"`
+
+	// Faces ignored by the ray intersection, "none", "back" or "front".
+	Cull string `json:"cull"`
+}
+
+type planeDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Point []float64 `json:"point"`
+	Normal []float64 `json:"normal"`
+}
+
+type diskDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Center []float64 `json:"center"`
+	Normal []float64 `json:"normal"`
+	Radius *float64 `json:"radius"`
+}
+
+type rectangleDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+
+	// Plane of the rectangle, "xy", "xz" or "yz".
+	Plane string `json:"plane"`
+
+	// Corners of the rectangle in the plane, using the axis in the order of the plane name.
+	Min []float64 `json:"min"`
+	Max []float64 `json:"max"`
+
+	// Position of the rectangle along the axis perpendicular to the plane.
+	Offset float64 `json:"offset"`
+}
+
+// Description shared by cylinders and cones, aligned with the Y axis.
+type cylinderDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+
+	// Center of the base.
+	Center []float64 `json:"center"`
+	Radius *float64 `json:"radius"`
+	Height *float64 `json:"height"`
+
+	// Closed by disks, true by default.
+	Capped *bool `json:"capped"`
+}
+
+type torusDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Center []float64 `json:"center"`
+	MajorRadius *float64 `json:"majorRadius"`
+	MinorRadius *float64 `json:"minorRadius"`
+}
+
+// Boolean operation between two solid objects, the type is the operation ("union", "intersection" or "difference").
+type csgDescription struct {
+	objectType
+	Left json.RawMessage `json:"left"`
+	Right json.RawMessage `json:"right"`
+}
+
+// Participating medium with constant density inside of a solid boundary, the material is the phase function.
+type mediumDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Density *float64 `json:"density"`
+	Boundary json.RawMessage `json:"boundary"`
+}
+
+// Heterogeneous medium from a density grid placed inside of a box, loaded from a file or generated from noise.
+type gridDescription struct {
+	objectType
+	Material json.RawMessage `json:"material"`
+	Min []float64 `json:"min"`
+	Max []float64 `json:"max"`
+
+	// Scale applied to the density of the grid, 1 by default.
+	Density *float64 `json:"density"`
+
+	// Path of the grid file, relative to the scene file. If empty the grid is generated from noise.
+	File string `json:"file"`
+
+	// Parameters of the noise grid, the number of voxels along each axis and the frequency of the noise.
+	Resolution *int `json:"resolution"`
+	Scale *float64 `json:"scale"`
+	Seed int64 `json:"seed"`
+}
@@ -0,0 +1,33 @@
+package scenefile
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Error found while reading a scene file, indicates the line where the problem was found.
+type Error struct {
+	// Name of the scene file.
+	File string
+
+	// Line of the scene file where the error was found (starting at 1).
+	Line int
+
+	// Description of the error.
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// Get the line number of a byte offset in the data.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
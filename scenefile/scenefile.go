@@ -0,0 +1,1546 @@
+package scenefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/faiface/pixel"
+	"gotracer/camera"
+	"gotracer/geometry"
+	"gotracer/material"
+	"gotracer/texture"
+	"gotracer/vmath"
+	"gotracer/wavefront"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Parser keeps the state while reading a scene file.
+type parser struct {
+	// Name of the file being read, used for error messages.
+	file string
+
+	// Content of the scene file.
+	data []byte
+
+	// Folder of the scene file, other files are loaded relative to it.
+	dir string
+
+	// Bounds of the output image, used to create the camera.
+	bounds pixel.Rect
+
+	// Materials declared in the scene file by name.
+	materials map[string]material.Material
+
+	// Image textures already loaded by file path, images used by multiple materials are only loaded once.
+	images map[string]*texture.ImageTexture
+
+	// Meshes already loaded, instances of the same mesh share the triangles.
+	meshes map[meshKey]*geometry.Mesh
+
+	// If true the camera is focused at the center of the image after the objects are created.
+	autofocus bool
+}
+
+// Identifies a mesh loaded from a file.
+type meshKey struct {
+	File string
+	Group string
+	Cull geometry.CullMode
+	Material material.Material
+}
+
+// JSON decoder reading a part of the scene file.
+type stream struct {
+	*json.Decoder
+
+	// Offset in the file of the first byte read by the decoder.
+	Base int64
+}
+
+// Create a decoder for data starting at a offset of the scene file.
+func newStream(data []byte, base int64) *stream {
+	var s = new(stream)
+	s.Decoder = json.NewDecoder(bytes.NewReader(data))
+	s.Base = base
+	return s
+}
+
+// Value read from the scene file and its position in the file.
+type section struct {
+	Name string
+	Offset int64
+	Raw json.RawMessage
+}
+
+// Load a scene description file.
+// The file describes the camera, materials and objects of the scene, errors indicate the line where they were found.
+// The bounds of the output image are used to create the camera.
+// Only JSON is supported, YAML files are rejected instead of failing with a JSON syntax error.
+func Load(fname string, bounds pixel.Rect) (*geometry.Scene, camera.Camera, error) {
+	var ext = strings.ToLower(filepath.Ext(fname))
+	if ext == ".yaml" || ext == ".yml" {
+		return nil, nil, fmt.Errorf("%s: YAML scene files are not supported, the scene has to be converted to JSON", fname)
+	}
+
+	var data, err = ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return Parse(data, fname, bounds)
+}
+
+// Parse a scene description from data.
+// The file name is used for error messages and to resolve the path of other files referenced by the scene.
+func Parse(data []byte, fname string, bounds pixel.Rect) (*geometry.Scene, camera.Camera, error) {
+	var p = new(parser)
+	p.file = fname
+	p.data = data
+	p.dir = filepath.Dir(fname)
+	p.bounds = bounds
+	p.materials = make(map[string]material.Material)
+	p.images = make(map[string]*texture.ImageTexture)
+	p.meshes = make(map[meshKey]*geometry.Mesh)
+
+	var cameraSection *section
+	var materialSections []*section
+	var objectSections []*section
+
+	var decoder = newStream(data, 0)
+
+	if err := p.expectDelim(decoder, '{'); err != nil {
+		return nil, nil, err
+	}
+
+	for decoder.More() {
+		var token, err = decoder.Token()
+		if err != nil {
+			return nil, nil, p.syntaxError(decoder, err)
+		}
+
+		var key = token.(string)
+		var offset = p.valueOffset(decoder)
+
+		switch key {
+		case "camera":
+			cameraSection, err = p.readValue(decoder, key)
+		case "materials":
+			materialSections, err = p.readObject(decoder)
+		case "objects":
+			objectSections, err = p.readArray(decoder)
+		default:
+			err = p.errorf(offset, "unknown section %q", key)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := p.expectDelim(decoder, '}'); err != nil {
+		return nil, nil, err
+	}
+
+	// Materials are created first to be referenced by the objects
+	for i := 0; i < len(materialSections); i++ {
+		var m, err = p.parseMaterial(materialSections[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		p.materials[materialSections[i].Name] = m
+	}
+
+	var c, err = p.parseCamera(cameraSection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Objects are placed in a scene graph, flattened into the scene
+	var root = geometry.NewNode("")
+
+	for i := 0; i < len(objectSections); i++ {
+		var node, err = p.parseObject(objectSections[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		root.Add(node)
+	}
+
+	var scene = root.Flatten()
+
+	// If nothing is found at the center of the image the focus distance is not changed
+	if p.autofocus {
+		camera.Focus(c, scene, 0.5, 0.5)
+	}
+
+	return scene, c, nil
+}
+
+// Create an error pointing to the line of a offset in the file.
+func (p *parser) errorf(offset int64, format string, args ...interface{}) error {
+	var e = new(Error)
+	e.File = p.file
+	e.Line = lineAt(p.data, offset)
+	e.Message = fmt.Sprintf(format, args...)
+	return e
+}
+
+// Create an error from a error returned by the JSON decoder.
+func (p *parser) syntaxError(decoder *stream, err error) error {
+	if syntax, ok := err.(*json.SyntaxError); ok {
+		return p.errorf(decoder.Base + syntax.Offset, "%s", syntax.Error())
+	}
+	return p.errorf(decoder.Base + decoder.InputOffset(), "%s", err.Error())
+}
+
+// Offset of the next value in the file, skips the whitespace and separators after the decoder position.
+func (p *parser) valueOffset(decoder *stream) int64 {
+	var offset = decoder.Base + decoder.InputOffset()
+
+	for offset < int64(len(p.data)) {
+		var c = p.data[offset]
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' && c != ':' && c != ',' {
+			break
+		}
+		offset++
+	}
+
+	return offset
+}
+
+// Read the next token and check if it is the expected delimiter.
+func (p *parser) expectDelim(decoder *stream, delim json.Delim) error {
+	var offset = p.valueOffset(decoder)
+	var token, err = decoder.Token()
+	if err != nil {
+		return p.syntaxError(decoder, err)
+	}
+
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return p.errorf(offset, "expected %q", delim.String())
+	}
+
+	return nil
+}
+
+// Read the next value as a raw section.
+func (p *parser) readValue(decoder *stream, name string) (*section, error) {
+	var s = new(section)
+	s.Name = name
+	s.Offset = p.valueOffset(decoder)
+
+	if err := decoder.Decode(&s.Raw); err != nil {
+		return nil, p.syntaxError(decoder, err)
+	}
+
+	return s, nil
+}
+
+// Read a JSON object, each value is stored as a section named by its key.
+func (p *parser) readObject(decoder *stream) ([]*section, error) {
+	var sections []*section
+
+	if err := p.expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	for decoder.More() {
+		var token, err = decoder.Token()
+		if err != nil {
+			return nil, p.syntaxError(decoder, err)
+		}
+
+		var s *section
+		s, err = p.readValue(decoder, token.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		sections = append(sections, s)
+	}
+
+	return sections, p.expectDelim(decoder, '}')
+}
+
+// Read a JSON array, each element is stored as a section.
+func (p *parser) readArray(decoder *stream) ([]*section, error) {
+	var sections []*section
+
+	if err := p.expectDelim(decoder, '['); err != nil {
+		return nil, err
+	}
+
+	for decoder.More() {
+		var s, err = p.readValue(decoder, "")
+		if err != nil {
+			return nil, err
+		}
+
+		sections = append(sections, s)
+	}
+
+	return sections, p.expectDelim(decoder, ']')
+}
+
+// Read the elements of a array field of a JSON object section.
+// Returns nil if the object does not have the field.
+func (p *parser) arrayField(s *section, key string) ([]*section, error) {
+	var decoder = newStream(s.Raw, s.Offset)
+
+	if err := p.expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	for decoder.More() {
+		var token, err = decoder.Token()
+		if err != nil {
+			return nil, p.syntaxError(decoder, err)
+		}
+
+		if token.(string) == key {
+			return p.readArray(decoder)
+		}
+
+		if _, err = p.readValue(decoder, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Read a field of a JSON object section.
+// Returns nil if the object does not have the field.
+func (p *parser) field(s *section, key string) (*section, error) {
+	var fields, err = p.readObject(newStream(s.Raw, s.Offset))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(fields); i++ {
+		if fields[i].Name == key {
+			return fields[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Decode a section into a description struct, unknown fields are reported as errors.
+func (p *parser) decode(s *section, v interface{}) error {
+	var decoder = json.NewDecoder(bytes.NewReader(s.Raw))
+	decoder.DisallowUnknownFields()
+
+	var err = decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	if typeError, ok := err.(*json.UnmarshalTypeError); ok {
+		return p.errorf(s.Offset + typeError.Offset, "invalid value for %q, expected %s", typeError.Field, typeError.Type.String())
+	}
+
+	return p.errorf(s.Offset, "%s", err.Error())
+}
+
+// Create a vector from a list of values, the list must have exactly three values.
+func (p *parser) vector(s *section, name string, values []float64) (*vmath.Vector3, error) {
+	if values == nil {
+		return nil, p.errorf(s.Offset, "missing %q", name)
+	}
+	if len(values) != 3 {
+		return nil, p.errorf(s.Offset, "%q must have 3 values, found %d", name, len(values))
+	}
+
+	return vmath.NewVector3(values[0], values[1], values[2]), nil
+}
+
+// Create a vector from a list of values, if the list is not specified the default value is used.
+func (p *parser) optionalVector(s *section, name string, values []float64, x float64, y float64, z float64) (*vmath.Vector3, error) {
+	if values == nil {
+		return vmath.NewVector3(x, y, z), nil
+	}
+
+	return p.vector(s, name, values)
+}
+
+// Create a direction vector from a list of values, the direction cannot be zero.
+func (p *parser) direction(s *section, name string, values []float64) (*vmath.Vector3, error) {
+	var v, err = p.vector(s, name, values)
+	if err != nil {
+		return nil, err
+	}
+	if v.SquaredLength() == 0 {
+		return nil, p.errorf(s.Offset, "%q cannot be zero", name)
+	}
+
+	return v, nil
+}
+
+// Create the camera from its description.
+// If the scene has no camera the default camera is used.
+func (p *parser) parseCamera(s *section) (camera.Camera, error) {
+	if s == nil {
+		return camera.NewCameraDefocusBounds(p.bounds), nil
+	}
+
+	var d cameraDescription
+	if err := p.decode(s, &d); err != nil {
+		return nil, err
+	}
+
+	var position, err = p.optionalVector(s, "position", d.Position, -0.15, 0.2, 0.15)
+	if err != nil {
+		return nil, err
+	}
+
+	var lookAt *vmath.Vector3
+	lookAt, err = p.optionalVector(s, "lookAt", d.LookAt, 0.0, 0.0, 0.0)
+	if err != nil {
+		return nil, err
+	}
+
+	var up *vmath.Vector3
+	up, err = p.optionalVector(s, "up", d.Up, 0.0, 1.0, 0.0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.ShutterClose < d.ShutterOpen {
+		return nil, p.errorf(s.Offset, "camera shutter cannot close before it opens")
+	}
+
+	// Defocus blur is only supported by the perspective camera
+	if d.Type != "" && d.Type != "perspective" && (d.Aperture != 0 || d.FocusDistance != nil) {
+		return nil, p.errorf(s.Offset, "camera aperture and focus distance are only supported by perspective cameras")
+	}
+	if d.Type != "" && d.Type != "perspective" && (d.Blades != 0 || d.ApertureFile != "" || d.Squeeze != nil || d.CatEye != 0) {
+		return nil, p.errorf(s.Offset, "camera aperture shape, squeeze and cat-eye are only supported by perspective cameras")
+	}
+	if d.Autofocus && (d.FocusDistance != nil || (d.Type != "" && d.Type != "perspective")) {
+		return nil, p.errorf(s.Offset, "camera autofocus is only supported by perspective cameras without a focus distance")
+	}
+	p.autofocus = d.Autofocus
+
+	if d.Type != "orthographic" && d.Height != nil {
+		return nil, p.errorf(s.Offset, "camera height is only supported by orthographic cameras")
+	}
+	if d.Type == "panoramic" && d.Fov != nil {
+		return nil, p.errorf(s.Offset, "panoramic cameras cover all directions and cannot have a fov")
+	}
+
+	var c camera.Camera
+
+	switch d.Type {
+	case "", "perspective":
+		var fov = 90.0
+		if d.Fov != nil {
+			fov = *d.Fov
+		}
+		if fov <= 0 || fov >= 180 {
+			return nil, p.errorf(s.Offset, "camera fov must be between 0 and 180 degrees")
+		}
+
+		if d.Aperture < 0 {
+			return nil, p.errorf(s.Offset, "camera aperture cannot be negative")
+		}
+
+		var direction = position.Clone()
+		direction.Sub(lookAt)
+
+		var focusDistance = direction.Length()
+		if d.FocusDistance != nil {
+			focusDistance = *d.FocusDistance
+		}
+		if focusDistance <= 0 {
+			return nil, p.errorf(s.Offset, "camera focus distance must be positive")
+		}
+
+		var defocus = camera.NewCameraDefocus(p.bounds, position, lookAt, up, fov, d.Aperture, focusDistance)
+
+		defocus.Shape, err = p.apertureShape(s, &d)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Squeeze != nil {
+			if *d.Squeeze <= 0 {
+				return nil, p.errorf(s.Offset, "camera squeeze must be positive")
+			}
+			defocus.Squeeze = *d.Squeeze
+		}
+
+		if d.CatEye < 0 {
+			return nil, p.errorf(s.Offset, "camera cat-eye cannot be negative")
+		}
+		defocus.CatEye = d.CatEye
+
+		c = defocus
+	case "orthographic":
+		if d.Fov != nil {
+			return nil, p.errorf(s.Offset, "orthographic cameras cannot have a fov, use the height")
+		}
+
+		// Same area as the default perspective camera (90 degrees) at the look at point
+		var direction = position.Clone()
+		direction.Sub(lookAt)
+
+		var height = 2.0 * direction.Length()
+		if d.Height != nil {
+			height = *d.Height
+		}
+		if height <= 0 {
+			return nil, p.errorf(s.Offset, "camera height must be positive")
+		}
+
+		c = camera.NewOrthographicCamera(p.bounds, position, lookAt, up, height)
+	case "fisheye":
+		var fov = 180.0
+		if d.Fov != nil {
+			fov = *d.Fov
+		}
+		if fov <= 0 || fov > 360 {
+			return nil, p.errorf(s.Offset, "fisheye camera fov must be between 0 and 360 degrees")
+		}
+
+		c = camera.NewFisheyeCamera(p.bounds, position, lookAt, up, fov)
+	case "panoramic":
+		c = camera.NewPanoramicCamera(p.bounds, position, lookAt, up)
+	default:
+		return nil, p.errorf(s.Offset, "unknown camera type %q", d.Type)
+	}
+
+	var view = c.GetView()
+	view.ShutterOpen = d.ShutterOpen
+	view.ShutterClose = d.ShutterClose
+
+	if d.Stereo != nil {
+		var stereo *section
+		stereo, err = p.field(s, "stereo")
+		if err != nil {
+			return nil, err
+		}
+		return p.parseStereo(stereo, c)
+	}
+
+	return c, nil
+}
+
+// Create the aperture shape of a camera, polygon blades or a image file.
+// Returns nil for circular apertures.
+func (p *parser) apertureShape(s *section, d *cameraDescription) (camera.ApertureShape, error) {
+	if d.Blades != 0 && d.ApertureFile != "" {
+		return nil, p.errorf(s.Offset, "camera aperture can have blades or a image file, not both")
+	}
+
+	if d.ApertureFile != "" {
+		var fname = d.ApertureFile
+		if !filepath.IsAbs(fname) {
+			fname = filepath.Join(p.dir, fname)
+		}
+
+		var shape, err = camera.LoadImageAperture(fname)
+		if err != nil {
+			return nil, p.errorf(s.Offset, "%s", err.Error())
+		}
+		return shape, nil
+	}
+
+	if d.Blades != 0 {
+		var shape, err = camera.NewPolygonAperture(d.Blades, d.BladeRotation)
+		if err != nil {
+			return nil, p.errorf(s.Offset, "camera %s", err.Error())
+		}
+		return shape, nil
+	}
+
+	if d.BladeRotation != 0 {
+		return nil, p.errorf(s.Offset, "camera blade rotation requires blades")
+	}
+
+	return nil, nil
+}
+
+// Create a stereo rig around the center camera from its description.
+func (p *parser) parseStereo(s *section, center camera.Camera) (camera.Camera, error) {
+	var d stereoDescription
+	if err := p.decode(s, &d); err != nil {
+		return nil, err
+	}
+
+	var separation = 0.064
+	if d.Separation != nil {
+		separation = *d.Separation
+	}
+	if separation < 0 {
+		return nil, p.errorf(s.Offset, "stereo separation cannot be negative")
+	}
+
+	var convergence camera.StereoConvergence
+	switch d.Convergence {
+	case "", "offAxis":
+		convergence = camera.StereoOffAxis
+	case "toeIn":
+		convergence = camera.StereoToeIn
+	default:
+		return nil, p.errorf(s.Offset, "unknown stereo convergence %q", d.Convergence)
+	}
+
+	var layout camera.StereoLayout
+	switch d.Layout {
+	case "", "sideBySide":
+		layout = camera.StereoSideBySide
+	case "overUnder":
+		layout = camera.StereoOverUnder
+	default:
+		return nil, p.errorf(s.Offset, "unknown stereo layout %q", d.Layout)
+	}
+
+	var c = camera.NewStereoCamera(center, separation, convergence, layout)
+
+	if d.ConvergenceDistance != nil {
+		if *d.ConvergenceDistance <= 0 {
+			return nil, p.errorf(s.Offset, "stereo convergence distance must be positive")
+		}
+		c.ConvergenceDistance = *d.ConvergenceDistance
+		c.UpdateViewport()
+	}
+
+	return c, nil
+}
+
+// Create a material from its description.
+func (p *parser) parseMaterial(s *section) (material.Material, error) {
+	var d materialDescription
+	if err := p.decode(s, &d); err != nil {
+		return nil, err
+	}
+
+	var tex, err = p.parseTexture(s, d.Texture)
+	if err != nil {
+		return nil, err
+	}
+
+	if tex != nil && d.Type != "lambert" && d.Type != "metal" && d.Type != "dieletric" && d.Type != "dielectric" && d.Type != "isotropic" && d.Type != "henyeyGreenstein" {
+		return nil, p.errorf(s.Offset, "material type %q does not support textures", d.Type)
+	}
+
+	switch d.Type {
+	case "lambert":
+		if tex != nil {
+			return material.NewLambertMaterialTexture(tex), nil
+		}
+		var albedo, err = p.vector(s, "albedo", d.Albedo)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewLambertMaterial(albedo), nil
+	case "metal":
+		if d.Fuzz < 0 {
+			return nil, p.errorf(s.Offset, "metal fuzz cannot be negative")
+		}
+		if tex != nil {
+			return material.NewMetalMaterialTexture(tex, d.Fuzz), nil
+		}
+		var albedo, err = p.vector(s, "albedo", d.Albedo)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewMetalMaterial(albedo, d.Fuzz), nil
+	case "dieletric", "dielectric":
+		var albedo, err = p.optionalVector(s, "albedo", d.Albedo, 1.0, 1.0, 1.0)
+		if err != nil {
+			return nil, err
+		}
+		if d.RefractiveIndice == nil {
+			return nil, p.errorf(s.Offset, "missing %q", "refractiveIndice")
+		}
+		if *d.RefractiveIndice <= 0 {
+			return nil, p.errorf(s.Offset, "refractive indice must be positive")
+		}
+		if tex != nil {
+			return material.NewDieletricMaterialTexture(*d.RefractiveIndice, tex), nil
+		}
+		return material.NewDieletricMaterial(*d.RefractiveIndice, albedo), nil
+	case "isotropic":
+		if tex != nil {
+			return material.NewIsotropicMaterialTexture(tex), nil
+		}
+		var albedo, err = p.vector(s, "albedo", d.Albedo)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewIsotropicMaterial(albedo), nil
+	case "henyeyGreenstein":
+		if d.G <= -1 || d.G >= 1 {
+			return nil, p.errorf(s.Offset, "henyeyGreenstein %q must be between -1 and 1", "g")
+		}
+		if tex != nil {
+			return material.NewHenyeyGreensteinMaterialTexture(tex, d.G), nil
+		}
+		var albedo, err = p.vector(s, "albedo", d.Albedo)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewHenyeyGreensteinMaterial(albedo, d.G), nil
+	case "light":
+		var color, err = p.vector(s, "color", d.Color)
+		if err != nil {
+			return nil, err
+		}
+		return material.NewLightMaterial(color), nil
+	case "normal":
+		return material.NewNormalMaterial(), nil
+	case "":
+		return nil, p.errorf(s.Offset, "missing material type")
+	}
+
+	return nil, p.errorf(s.Offset, "unknown material type %q", d.Type)
+}
+
+// Create a texture from its description, returns nil if no texture was specified.
+func (p *parser) parseTexture(s *section, raw json.RawMessage) (texture.Texture, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var d textureDescription
+	var ts = new(section)
+	ts.Offset = s.Offset
+	ts.Raw = raw
+
+	if err := p.decode(ts, &d); err != nil {
+		return nil, err
+	}
+
+	switch d.Type {
+	case "solid":
+		var color, err = p.vector(s, "color", d.Color)
+		if err != nil {
+			return nil, err
+		}
+		return texture.NewSolidTexture(color), nil
+	case "image":
+		if d.File == "" {
+			return nil, p.errorf(s.Offset, "missing %q", "file")
+		}
+
+		var fname = d.File
+		if !filepath.IsAbs(fname) {
+			fname = filepath.Join(p.dir, fname)
+		}
+
+		var image, ok = p.images[fname]
+		if !ok {
+			var err error
+			image, err = texture.LoadImageTexture(fname)
+			if err != nil {
+				return nil, p.errorf(s.Offset, "%s", err.Error())
+			}
+			p.images[fname] = image
+		}
+
+		// Copy the texture to allow different wrap and filter modes sharing the same pixels
+		var t = *image
+
+		switch d.Wrap {
+		case "", "repeat":
+			t.WrapU, t.WrapV = texture.WrapRepeat, texture.WrapRepeat
+		case "clamp":
+			t.WrapU, t.WrapV = texture.WrapClamp, texture.WrapClamp
+		case "mirror":
+			t.WrapU, t.WrapV = texture.WrapMirror, texture.WrapMirror
+		default:
+			return nil, p.errorf(s.Offset, "unknown wrap mode %q", d.Wrap)
+		}
+
+		switch d.Filter {
+		case "", "bilinear":
+			t.Filter = texture.FilterBilinear
+		case "nearest":
+			t.Filter = texture.FilterNearest
+		default:
+			return nil, p.errorf(s.Offset, "unknown filter %q", d.Filter)
+		}
+
+		return &t, nil
+	case "checker":
+		var odd, err = p.checkerCell(s, "odd", d.Odd)
+		if err != nil {
+			return nil, err
+		}
+		even, err := p.checkerCell(s, "even", d.Even)
+		if err != nil {
+			return nil, err
+		}
+		scale, err := p.positive(s, "scale", d.Scale, 1.0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch d.Space {
+		case "", "world":
+			return texture.NewCheckerTexture(odd, even, scale), nil
+		case "uv":
+			return texture.NewUVCheckerTexture(odd, even, scale, scale), nil
+		}
+		return nil, p.errorf(s.Offset, "unknown checker space %q", d.Space)
+	case "noise", "turbulence":
+		var color, err = p.optionalVector(s, "color", d.Color, 1.0, 1.0, 1.0)
+		if err != nil {
+			return nil, err
+		}
+		scale, err := p.positive(s, "scale", d.Scale, 1.0)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Type == "noise" {
+			return texture.NewNoiseTexture(texture.NewPerlin(d.Seed), scale, color), nil
+		}
+
+		var depth = 7
+		if d.Depth != nil {
+			if *d.Depth < 1 {
+				return nil, p.errorf(s.Offset, "%q must be at least 1", "depth")
+			}
+			depth = *d.Depth
+		}
+		return texture.NewTurbulenceTexture(texture.NewPerlin(d.Seed), scale, depth, color), nil
+	case "marble", "wood", "worley":
+		var colorA, err = p.optionalVector(s, "colorA", d.ColorA, 1.0, 1.0, 1.0)
+		if err != nil {
+			return nil, err
+		}
+		colorB, err := p.optionalVector(s, "colorB", d.ColorB, 0.0, 0.0, 0.0)
+		if err != nil {
+			return nil, err
+		}
+		scale, err := p.positive(s, "scale", d.Scale, 1.0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch d.Type {
+		case "marble":
+			var turbulence = 5.0
+			if d.Turbulence != nil {
+				turbulence = *d.Turbulence
+			}
+			return texture.NewMarbleTexture(texture.NewPerlin(d.Seed), scale, turbulence, colorA, colorB), nil
+		case "wood":
+			var noise = 1.0
+			if d.Noise != nil {
+				noise = *d.Noise
+			}
+			return texture.NewWoodTexture(texture.NewPerlin(d.Seed), scale, noise, colorA, colorB), nil
+		}
+		return texture.NewWorleyTexture(scale, d.Seed, colorA, colorB), nil
+	case "":
+		return nil, p.errorf(s.Offset, "missing texture type")
+	}
+
+	return nil, p.errorf(s.Offset, "unknown texture type %q", d.Type)
+}
+
+// Get the material of a object, can be the name of a material or a inline material description.
+func (p *parser) objectMaterial(s *section, raw json.RawMessage) (material.Material, error) {
+	if raw == nil {
+		return nil, p.errorf(s.Offset, "missing %q", "material")
+	}
+
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		var m, ok = p.materials[name]
+		if !ok {
+			return nil, p.errorf(s.Offset, "unknown material %q", name)
+		}
+		return m, nil
+	}
+
+	var inline = new(section)
+	inline.Offset = s.Offset
+	inline.Raw = raw
+	return p.parseMaterial(inline)
+}
+
+// Create the scene graph node of a object from its description.
+// Groups create a node with the nodes of their children.
+func (p *parser) parseObject(s *section) (*geometry.Node, error) {
+	var t objectType
+	if err := json.Unmarshal(s.Raw, &t); err != nil {
+		return nil, p.errorf(s.Offset, "object must be a JSON object")
+	}
+
+	var node *geometry.Node
+
+	if t.Type == "group" {
+		var d groupDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+
+		var children, err = p.arrayField(s, "children")
+		if err != nil {
+			return nil, err
+		}
+
+		node = geometry.NewNode(t.Name)
+
+		for i := 0; i < len(children); i++ {
+			var child *geometry.Node
+			child, err = p.parseObject(children[i])
+			if err != nil {
+				return nil, err
+			}
+			node.Add(child)
+		}
+	} else {
+		var object, err = p.createObject(s, t.Type)
+		if err != nil {
+			return nil, err
+		}
+		node = geometry.NewObjectNode(t.Name, object)
+	}
+
+	if t.Motion != nil {
+		if node.Object == nil {
+			return nil, p.errorf(s.Offset, "groups cannot have a %q, add it to the objects of the group", "motion")
+		}
+		var object, err = p.movingObject(s, node.Object, t.Transform, t.Motion)
+		if err != nil {
+			return nil, err
+		}
+		node.Object = object
+	} else if t.Transform != nil {
+		var transform, err = p.parseTransform(s, t.Transform)
+		if err != nil {
+			return nil, err
+		}
+		node.Transform = transform
+	}
+
+	if t.Enabled != nil {
+		node.Enabled = *t.Enabled
+	}
+
+	return node, nil
+}
+
+// Create the geometry of a object from its description.
+func (p *parser) createObject(s *section, objectType string) (geometry.Hitable, error) {
+	switch objectType {
+	case "sphere":
+		var d sphereDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var center, err = p.vector(s, "center", d.Center)
+		if err != nil {
+			return nil, err
+		}
+		if d.Radius == nil || *d.Radius <= 0 {
+			return nil, p.errorf(s.Offset, "sphere radius must be positive")
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewSphere(*d.Radius, center, m), nil
+	case "box":
+		var d boxDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var min, err = p.vector(s, "min", d.Min)
+		if err != nil {
+			return nil, err
+		}
+		var max *vmath.Vector3
+		max, err = p.vector(s, "max", d.Max)
+		if err != nil {
+			return nil, err
+		}
+		if min.X > max.X || min.Y > max.Y || min.Z > max.Z {
+			return nil, p.errorf(s.Offset, "box min must be smaller than max")
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewBox(min, max, m), nil
+	case "triangle":
+		var d triangleDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var a, err = p.vector(s, "a", d.A)
+		if err != nil {
+			return nil, err
+		}
+		var b *vmath.Vector3
+		b, err = p.vector(s, "b", d.B)
+		if err != nil {
+			return nil, err
+		}
+		var c *vmath.Vector3
+		c, err = p.vector(s, "c", d.C)
+		if err != nil {
+			return nil, err
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		var triangle = geometry.NewTriangle(a, b, c, m)
+		triangle.Cull, err = p.cullMode(s, d.Cull)
+		if err != nil {
+			return nil, err
+		}
+		return triangle, nil
+	case "obj":
+		var d meshDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		if d.File == "" {
+			return nil, p.errorf(s.Offset, "missing %q", "file")
+		}
+		// The material is optional, it is only used for faces without material in the MTL files
+		var m material.Material
+		if d.Material != nil {
+			var err error
+			m, err = p.objectMaterial(s, d.Material)
+			if err != nil {
+				return nil, err
+			}
+		}
+		var cull, err = p.cullMode(s, d.Cull)
+		if err != nil {
+			return nil, err
+		}
+		var mesh *geometry.Mesh
+		mesh, err = p.loadOBJ(d.File, d.Group, cull, m)
+		if err != nil {
+			return nil, p.errorf(s.Offset, "%s", err.Error())
+		}
+		return mesh, nil
+	case "plane":
+		var d planeDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var point, err = p.optionalVector(s, "point", d.Point, 0.0, 0.0, 0.0)
+		if err != nil {
+			return nil, err
+		}
+		var normal *vmath.Vector3
+		normal, err = p.direction(s, "normal", d.Normal)
+		if err != nil {
+			return nil, err
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewPlane(point, normal, m), nil
+	case "disk":
+		var d diskDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var center, err = p.vector(s, "center", d.Center)
+		if err != nil {
+			return nil, err
+		}
+		var normal *vmath.Vector3
+		normal, err = p.direction(s, "normal", d.Normal)
+		if err != nil {
+			return nil, err
+		}
+		var radius float64
+		radius, err = p.requiredPositive(s, "radius", d.Radius)
+		if err != nil {
+			return nil, err
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewDisk(center, normal, radius, m), nil
+	case "rectangle":
+		var d rectangleDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var axis int
+		switch d.Plane {
+		case "xy":
+			axis = 2
+		case "xz":
+			axis = 1
+		case "yz":
+			axis = 0
+		default:
+			return nil, p.errorf(s.Offset, "unknown rectangle plane %q, expected \"xy\", \"xz\" or \"yz\"", d.Plane)
+		}
+		if len(d.Min) != 2 || len(d.Max) != 2 {
+			return nil, p.errorf(s.Offset, "rectangle %q and %q must have 2 values", "min", "max")
+		}
+		if d.Min[0] >= d.Max[0] || d.Min[1] >= d.Max[1] {
+			return nil, p.errorf(s.Offset, "rectangle min must be smaller than max")
+		}
+		var m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewRectangle(axis, vmath.NewVector2(d.Min[0], d.Min[1]), vmath.NewVector2(d.Max[0], d.Max[1]), d.Offset, m), nil
+	case "cylinder", "cone":
+		var d cylinderDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var center, err = p.optionalVector(s, "center", d.Center, 0.0, 0.0, 0.0)
+		if err != nil {
+			return nil, err
+		}
+		var radius float64
+		radius, err = p.requiredPositive(s, "radius", d.Radius)
+		if err != nil {
+			return nil, err
+		}
+		var height float64
+		height, err = p.requiredPositive(s, "height", d.Height)
+		if err != nil {
+			return nil, err
+		}
+		var capped = true
+		if d.Capped != nil {
+			capped = *d.Capped
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		if objectType == "cone" {
+			return geometry.NewCone(center, radius, height, capped, m), nil
+		}
+		return geometry.NewCylinder(center, radius, height, capped, m), nil
+	case "torus":
+		var d torusDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var center, err = p.optionalVector(s, "center", d.Center, 0.0, 0.0, 0.0)
+		if err != nil {
+			return nil, err
+		}
+		var major float64
+		major, err = p.requiredPositive(s, "majorRadius", d.MajorRadius)
+		if err != nil {
+			return nil, err
+		}
+		var minor float64
+		minor, err = p.requiredPositive(s, "minorRadius", d.MinorRadius)
+		if err != nil {
+			return nil, err
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewTorus(center, major, minor, m), nil
+	case "grid":
+		var d gridDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var min, err = p.vector(s, "min", d.Min)
+		if err != nil {
+			return nil, err
+		}
+		var max *vmath.Vector3
+		max, err = p.vector(s, "max", d.Max)
+		if err != nil {
+			return nil, err
+		}
+		if min.X >= max.X || min.Y >= max.Y || min.Z >= max.Z {
+			return nil, p.errorf(s.Offset, "grid min must be smaller than max")
+		}
+		var density float64
+		density, err = p.positive(s, "density", d.Density, 1.0)
+		if err != nil {
+			return nil, err
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		var box = geometry.NewAABB(min, max)
+		if d.File != "" {
+			if d.Resolution != nil || d.Scale != nil || d.Seed != 0 {
+				return nil, p.errorf(s.Offset, "grid loaded from a file cannot have noise parameters")
+			}
+			var fname = d.File
+			if !filepath.IsAbs(fname) {
+				fname = filepath.Join(p.dir, fname)
+			}
+			var grid *geometry.GridVolume
+			grid, err = geometry.LoadGridVolume(fname, box, density, m)
+			if err != nil {
+				return nil, p.errorf(s.Offset, "%s", err.Error())
+			}
+			return grid, nil
+		}
+		var resolution = 32
+		if d.Resolution != nil {
+			if *d.Resolution <= 0 {
+				return nil, p.errorf(s.Offset, "%q must be positive", "resolution")
+			}
+			resolution = *d.Resolution
+		}
+		var scale float64
+		scale, err = p.positive(s, "scale", d.Scale, 4.0)
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewNoiseGridVolume(box, resolution, texture.NewPerlin(d.Seed), scale, density, m), nil
+	case "union", "intersection", "difference":
+		var d csgDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		return p.parseCSG(s, objectType)
+	case "medium":
+		var d mediumDescription
+		if err := p.decode(s, &d); err != nil {
+			return nil, err
+		}
+		var density, err = p.requiredPositive(s, "density", d.Density)
+		if err != nil {
+			return nil, err
+		}
+		var m material.Material
+		m, err = p.objectMaterial(s, d.Material)
+		if err != nil {
+			return nil, err
+		}
+		var boundary geometry.Solid
+		boundary, err = p.solidField(s, "boundary")
+		if err != nil {
+			return nil, err
+		}
+		return geometry.NewConstantMedium(boundary, density, m), nil
+	case "":
+		return nil, p.errorf(s.Offset, "missing object type")
+	}
+
+	return nil, p.errorf(s.Offset, "unknown object type %q", objectType)
+}
+
+// Create a CSG node combining the left and right objects.
+func (p *parser) parseCSG(s *section, operation string) (geometry.Hitable, error) {
+	var left, err = p.solidField(s, "left")
+	if err != nil {
+		return nil, err
+	}
+
+	var right geometry.Solid
+	right, err = p.solidField(s, "right")
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case "intersection":
+		return geometry.NewCSG(geometry.CSGIntersection, left, right), nil
+	case "difference":
+		return geometry.NewCSG(geometry.CSGDifference, left, right), nil
+	}
+	return geometry.NewCSG(geometry.CSGUnion, left, right), nil
+}
+
+// Create a solid object from a field of a object description, used by CSG operands and medium boundaries.
+// The transform of the solid is applied using a instance, groups cannot be used as solids.
+func (p *parser) solidField(s *section, key string) (geometry.Solid, error) {
+	var field, err = p.field(s, key)
+	if err != nil {
+		return nil, err
+	}
+	if field == nil {
+		return nil, p.errorf(s.Offset, "missing %q", key)
+	}
+
+	var node *geometry.Node
+	node, err = p.parseObject(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if node.Object == nil {
+		return nil, p.errorf(field.Offset, "%q cannot be a group", key)
+	}
+	if !node.Enabled {
+		return nil, p.errorf(field.Offset, "%q cannot be disabled", key)
+	}
+	if !geometry.IsSolid(node.Object) {
+		return nil, p.errorf(field.Offset, "%q is not a solid object", key)
+	}
+
+	var object = node.Object
+	if !node.Transform.IsIdentity() {
+		object = geometry.NewInstance(object, node.Transform)
+	}
+
+	return object.(geometry.Solid), nil
+}
+
+// Load a obj file as a mesh, the path is relative to the scene file.
+// If a group is specified only the faces of that group are loaded.
+// Meshes are only loaded once, objects using the same file, group, cull mode and material share the mesh.
+func (p *parser) loadOBJ(fname string, group string, cull geometry.CullMode, m material.Material) (*geometry.Mesh, error) {
+	if !filepath.IsAbs(fname) {
+		fname = filepath.Join(p.dir, fname)
+	}
+
+	var key = meshKey{File: fname, Group: group, Cull: cull, Material: m}
+	if mesh, ok := p.meshes[key]; ok {
+		return mesh, nil
+	}
+
+	var object, err = wavefront.Load(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	var mesh *geometry.Mesh
+	if group != "" {
+		mesh, err = object.GroupMesh(group, m)
+	} else {
+		mesh, err = object.Mesh(m)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mesh.Cull = cull
+	p.meshes[key] = mesh
+
+	return mesh, nil
+}
+
+// Create a transformation matrix from its description.
+// The transform is a matrix or a combination of scale, rotation (degrees around X, Y and then Z) and translation applied in this order.
+func (p *parser) parseTransform(s *section, raw json.RawMessage) (*vmath.Matrix4, error) {
+	var d transformDescription
+	var ts = new(section)
+	ts.Offset = s.Offset
+	ts.Raw = raw
+
+	if err := p.decode(ts, &d); err != nil {
+		return nil, err
+	}
+
+	var transform = vmath.NewMatrix4()
+
+	if d.Matrix != nil {
+		if d.Translate != nil || d.Rotate != nil || d.Scale != nil {
+			return nil, p.errorf(s.Offset, "transform %q cannot be combined with other transforms", "matrix")
+		}
+		if len(d.Matrix) != 16 {
+			return nil, p.errorf(s.Offset, "%q must have 16 values, found %d", "matrix", len(d.Matrix))
+		}
+		copy(transform.Values[:], d.Matrix)
+	} else {
+		var components, err = p.transformComponents(s, d.Translate, d.Rotate, d.Scale, vmath.NewTransform())
+		if err != nil {
+			return nil, err
+		}
+		transform = components.Matrix()
+	}
+
+	if _, ok := transform.Inverse(); !ok {
+		return nil, p.errorf(s.Offset, "transform cannot be inverted")
+	}
+
+	return transform, nil
+}
+
+// Read the translation, rotation (in degrees) and scale of a transform.
+// Components that are not specified keep the value of the base transform.
+func (p *parser) transformComponents(s *section, translate []float64, rotate []float64, scale json.RawMessage, base *vmath.Transform) (*vmath.Transform, error) {
+	if translate != nil {
+		var v, err = p.vector(s, "translate", translate)
+		if err != nil {
+			return nil, err
+		}
+		base.Translation = v
+	}
+
+	if rotate != nil {
+		var v, err = p.vector(s, "rotate", rotate)
+		if err != nil {
+			return nil, err
+		}
+		v.MulScalar(math.Pi / 180.0)
+		base.Rotation = v
+	}
+
+	if scale != nil {
+		// Scale can be a single number for uniform scaling
+		var uniform float64
+		var values []float64
+		if json.Unmarshal(scale, &uniform) == nil {
+			values = []float64{uniform, uniform, uniform}
+		} else if json.Unmarshal(scale, &values) != nil {
+			return nil, p.errorf(s.Offset, "%q must be a number or a list of 3 values", "scale")
+		}
+		var v, err = p.vector(s, "scale", values)
+		if err != nil {
+			return nil, err
+		}
+		base.Scale = v
+	}
+
+	return base, nil
+}
+
+// Create a object that moves from its transform to the motion transform, used for motion blur.
+// Spheres and boxes that are only translated are replaced by moving spheres and boxes, other objects use moving instances.
+func (p *parser) movingObject(s *section, object geometry.Hitable, transform json.RawMessage, motion json.RawMessage) (geometry.Hitable, error) {
+	var start = vmath.NewTransform()
+
+	if transform != nil {
+		var d transformDescription
+		var ts = new(section)
+		ts.Offset = s.Offset
+		ts.Raw = transform
+		if err := p.decode(ts, &d); err != nil {
+			return nil, err
+		}
+		if d.Matrix != nil {
+			return nil, p.errorf(s.Offset, "transform %q cannot be used with a motion", "matrix")
+		}
+
+		var err error
+		start, err = p.transformComponents(s, d.Translate, d.Rotate, d.Scale, start)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var d motionDescription
+	var ms = new(section)
+	ms.Offset = s.Offset
+	ms.Raw = motion
+	if err := p.decode(ms, &d); err != nil {
+		return nil, err
+	}
+
+	var end, err = p.transformComponents(s, d.Translate, d.Rotate, d.Scale, start.Clone())
+	if err != nil {
+		return nil, err
+	}
+
+	var time0, time1 = 0.0, 1.0
+	if d.Time != nil {
+		if len(d.Time) != 2 || d.Time[0] > d.Time[1] {
+			return nil, p.errorf(s.Offset, "motion %q must be a start and a end time", "time")
+		}
+		time0, time1 = d.Time[0], d.Time[1]
+	}
+
+	if start.Scale.X * end.Scale.X <= 0 || start.Scale.Y * end.Scale.Y <= 0 || start.Scale.Z * end.Scale.Z <= 0 {
+		return nil, p.errorf(s.Offset, "motion scale cannot be zero or change sign")
+	}
+
+	// Objects that are only translated do not need a moving instance
+	var identity = vmath.NewTransform()
+	if *start.Rotation == *identity.Rotation && *end.Rotation == *identity.Rotation && *start.Scale == *identity.Scale && *end.Scale == *identity.Scale {
+		switch o := object.(type) {
+		case *geometry.Sphere:
+			var center0 = o.Center.Clone()
+			center0.Add(start.Translation)
+			var center1 = o.Center.Clone()
+			center1.Add(end.Translation)
+			return geometry.NewMovingSphere(o.Radius, center0, center1, time0, time1, o.Material), nil
+		case *geometry.Box:
+			var min0, max0 = o.Min.Clone(), o.Max.Clone()
+			min0.Add(start.Translation)
+			max0.Add(start.Translation)
+			var min1, max1 = o.Min.Clone(), o.Max.Clone()
+			min1.Add(end.Translation)
+			max1.Add(end.Translation)
+			return geometry.NewMovingBox(min0, max0, min1, max1, time0, time1, o.Material), nil
+		}
+	}
+
+	return geometry.NewMovingInstance(object, start, end, time0, time1), nil
+}
+
+// Get the triangle cull mode from its name, triangles are double sided if not specified.
+func (p *parser) cullMode(s *section, name string) (geometry.CullMode, error) {
+	switch name {
+	case "", "none":
+		return geometry.CullNone, nil
+	case "back":
+		return geometry.CullBack, nil
+	case "front":
+		return geometry.CullFront, nil
+	}
+
+	return geometry.CullNone, p.errorf(s.Offset, "unknown cull mode %q", name)
+}
+
+// Create the texture of a checker cell, the cell can be a color or a nested texture.
+func (p *parser) checkerCell(s *section, name string, raw json.RawMessage) (texture.Texture, error) {
+	if raw == nil {
+		return nil, p.errorf(s.Offset, "missing %q", name)
+	}
+
+	if raw[0] == '[' {
+		var values []float64
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, p.errorf(s.Offset, "%q must be a color or a texture", name)
+		}
+		var color, err = p.vector(s, name, values)
+		if err != nil {
+			return nil, err
+		}
+		return texture.NewSolidTexture(color), nil
+	}
+
+	return p.parseTexture(s, raw)
+}
+
+// Read a positive number, if the number is not specified the default value is used.
+func (p *parser) positive(s *section, name string, value *float64, def float64) (float64, error) {
+	if value == nil {
+		return def, nil
+	}
+	if *value <= 0 {
+		return 0, p.errorf(s.Offset, "%q must be positive", name)
+	}
+
+	return *value, nil
+}
+
+// Read a positive number that must be specified.
+func (p *parser) requiredPositive(s *section, name string, value *float64) (float64, error) {
+	if value == nil {
+		return 0, p.errorf(s.Offset, "missing %q", name)
+	}
+
+	return p.positive(s, name, value, 0.0)
+}
@@ -0,0 +1,68 @@
+package texture
+
+import (
+	"gotracer/vmath"
+	"math"
+)
+
+// Checker texture alternates between two textures in a 3D grid of cubes in world space.
+type CheckerTexture struct {
+	// Texture used in the odd cells.
+	Odd Texture
+
+	// Texture used in the even cells.
+	Even Texture
+
+	// Number of cells per world unit.
+	Scale float64
+}
+
+func NewCheckerTexture(odd Texture, even Texture, scale float64) *CheckerTexture {
+	var t = new(CheckerTexture)
+	t.Odd = odd
+	t.Even = even
+	t.Scale = scale
+	return t
+}
+
+func (t *CheckerTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var cell = int(math.Floor(p.X * t.Scale)) + int(math.Floor(p.Y * t.Scale)) + int(math.Floor(p.Z * t.Scale))
+
+	if cell % 2 == 0 {
+		return t.Even.Value(u, v, p)
+	}
+	return t.Odd.Value(u, v, p)
+}
+
+// UV checker texture alternates between two textures in a grid over the surface texture coordinates.
+type UVCheckerTexture struct {
+	// Texture used in the odd cells.
+	Odd Texture
+
+	// Texture used in the even cells.
+	Even Texture
+
+	// Number of cells along the U coordinate.
+	ScaleU float64
+
+	// Number of cells along the V coordinate.
+	ScaleV float64
+}
+
+func NewUVCheckerTexture(odd Texture, even Texture, scaleU float64, scaleV float64) *UVCheckerTexture {
+	var t = new(UVCheckerTexture)
+	t.Odd = odd
+	t.Even = even
+	t.ScaleU = scaleU
+	t.ScaleV = scaleV
+	return t
+}
+
+func (t *UVCheckerTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var cell = int(math.Floor(u * t.ScaleU)) + int(math.Floor(v * t.ScaleV))
+
+	if cell % 2 == 0 {
+		return t.Even.Value(u, v, p)
+	}
+	return t.Odd.Value(u, v, p)
+}
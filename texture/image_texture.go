@@ -0,0 +1,167 @@
+package texture
+
+import (
+	"gotracer/vmath"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+)
+
+// Wrap modes indicate how coordinates outside of the [0, 1] range are handled.
+const (
+	// Texture is repeated.
+	WrapRepeat = iota
+
+	// Coordinates are clamped to the edge of the texture.
+	WrapClamp
+
+	// Texture is repeated and mirrored on each repetition.
+	WrapMirror
+)
+
+// Filter modes indicate how the texture is sampled between pixels.
+const (
+	// Color of the nearest pixel.
+	FilterNearest = iota
+
+	// Bilinear interpolation of the four nearest pixels.
+	FilterBilinear
+)
+
+// Image texture samples its color from a image.
+// The image colors are converted to linear space when loaded.
+type ImageTexture struct {
+	// Width of the image in pixels.
+	Width int
+
+	// Height of the image in pixels.
+	Height int
+
+	// Linear color of the image pixels, stored row by row from the top of the image.
+	Pix []vmath.Vector3
+
+	// Wrap mode used for the U coordinate.
+	WrapU int
+
+	// Wrap mode used for the V coordinate.
+	WrapV int
+
+	// Filter used to sample the image.
+	Filter int
+}
+
+// Create new image texture from a image.
+func NewImageTexture(img image.Image) *ImageTexture {
+	var t = new(ImageTexture)
+	var bounds = img.Bounds()
+
+	t.Width = bounds.Dx()
+	t.Height = bounds.Dy()
+	t.Pix = make([]vmath.Vector3, t.Width * t.Height)
+	t.WrapU = WrapRepeat
+	t.WrapV = WrapRepeat
+	t.Filter = FilterBilinear
+
+	for y := 0; y < t.Height; y++ {
+		for x := 0; x < t.Width; x++ {
+			var r, g, b, _ = img.At(bounds.Min.X + x, bounds.Min.Y + y).RGBA()
+			t.Pix[y * t.Width + x].Set(Linear(float64(r) / 0xffff), Linear(float64(g) / 0xffff), Linear(float64(b) / 0xffff))
+		}
+	}
+
+	return t
+}
+
+// Load a image texture from a PNG or JPEG file.
+func LoadImageTexture(fname string) (*ImageTexture, error) {
+	var file, err = os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var img image.Image
+	img, _, err = image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewImageTexture(img), nil
+}
+
+// The V coordinate starts from the bottom of the image.
+func (t *ImageTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	if t.Width == 0 || t.Height == 0 {
+		return vmath.NewVector3(0.0, 1.0, 1.0)
+	}
+
+	var x = u * float64(t.Width)
+	var y = (1.0 - v) * float64(t.Height)
+
+	if t.Filter == FilterNearest {
+		return t.Pixel(int(math.Floor(x)), int(math.Floor(y)))
+	}
+
+	// Pixel centers are at half coordinates
+	x -= 0.5
+	y -= 0.5
+
+	var x0 = math.Floor(x)
+	var y0 = math.Floor(y)
+	var fx = x - x0
+	var fy = y - y0
+
+	var c00 = t.Pixel(int(x0), int(y0))
+	var c10 = t.Pixel(int(x0) + 1, int(y0))
+	var c01 = t.Pixel(int(x0), int(y0) + 1)
+	var c11 = t.Pixel(int(x0) + 1, int(y0) + 1)
+
+	c00.MulScalar((1.0 - fx) * (1.0 - fy))
+	c10.MulScalar(fx * (1.0 - fy))
+	c01.MulScalar((1.0 - fx) * fy)
+	c11.MulScalar(fx * fy)
+
+	c00.Add(c10)
+	c00.Add(c01)
+	c00.Add(c11)
+	return c00
+}
+
+// Get the color of a pixel, coordinates outside of the image are handled by the wrap mode.
+func (t *ImageTexture) Pixel(x int, y int) *vmath.Vector3 {
+	x = wrap(x, t.Width, t.WrapU)
+	y = wrap(y, t.Height, t.WrapV)
+	return t.Pix[y * t.Width + x].Clone()
+}
+
+// Wrap a pixel coordinate into the [0, size[ range.
+func wrap(i int, size int, mode int) int {
+	switch mode {
+	case WrapClamp:
+		if i < 0 {
+			return 0
+		} else if i >= size {
+			return size - 1
+		}
+		return i
+	case WrapMirror:
+		var period = size * 2
+		i = ((i % period) + period) % period
+		if i >= size {
+			i = period - 1 - i
+		}
+		return i
+	}
+
+	return ((i % size) + size) % size
+}
+
+// Convert a sRGB encoded value into linear space.
+func Linear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v + 0.055) / 1.055, 2.4)
+}
@@ -0,0 +1,145 @@
+package texture
+
+import (
+	"gotracer/vmath"
+	"math"
+)
+
+// Noise texture modulates a color with perlin noise.
+type NoiseTexture struct {
+	Perlin *Perlin
+
+	// Frequency of the noise.
+	Scale float64
+
+	// Color modulated by the noise.
+	Color *vmath.Vector3
+}
+
+func NewNoiseTexture(perlin *Perlin, scale float64, color *vmath.Vector3) *NoiseTexture {
+	var t = new(NoiseTexture)
+	t.Perlin = perlin
+	t.Scale = scale
+	t.Color = color
+	return t
+}
+
+func (t *NoiseTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var point = p.Clone()
+	point.MulScalar(t.Scale)
+
+	var color = t.Color.Clone()
+	color.MulScalar(0.5 * (1.0 + t.Perlin.Noise(point)))
+	return color
+}
+
+// Turbulence texture modulates a color with multiple octaves of perlin noise.
+type TurbulenceTexture struct {
+	Perlin *Perlin
+
+	// Frequency of the noise.
+	Scale float64
+
+	// Number of noise octaves.
+	Depth int
+
+	// Color modulated by the turbulence.
+	Color *vmath.Vector3
+}
+
+func NewTurbulenceTexture(perlin *Perlin, scale float64, depth int, color *vmath.Vector3) *TurbulenceTexture {
+	var t = new(TurbulenceTexture)
+	t.Perlin = perlin
+	t.Scale = scale
+	t.Depth = depth
+	t.Color = color
+	return t
+}
+
+func (t *TurbulenceTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var point = p.Clone()
+	point.MulScalar(t.Scale)
+
+	var color = t.Color.Clone()
+	color.MulScalar(math.Min(t.Perlin.Turbulence(point, t.Depth), 1.0))
+	return color
+}
+
+// Marble texture creates veins along the Z axis, disturbed by turbulence.
+type MarbleTexture struct {
+	Perlin *Perlin
+
+	// Frequency of the veins.
+	Scale float64
+
+	// Intensity of the turbulence applied to the veins.
+	Turbulence float64
+
+	// Number of turbulence octaves.
+	Depth int
+
+	// Color of the base and of the veins.
+	ColorA *vmath.Vector3
+	ColorB *vmath.Vector3
+}
+
+func NewMarbleTexture(perlin *Perlin, scale float64, turbulence float64, colorA *vmath.Vector3, colorB *vmath.Vector3) *MarbleTexture {
+	var t = new(MarbleTexture)
+	t.Perlin = perlin
+	t.Scale = scale
+	t.Turbulence = turbulence
+	t.Depth = 7
+	t.ColorA = colorA
+	t.ColorB = colorB
+	return t
+}
+
+func (t *MarbleTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var phase = t.Scale * p.Z + t.Turbulence * t.Perlin.Turbulence(p, t.Depth)
+	return Lerp(t.ColorA, t.ColorB, 0.5 * (1.0 + math.Sin(phase)))
+}
+
+// Wood texture creates concentric rings around the Y axis, disturbed by noise.
+type WoodTexture struct {
+	Perlin *Perlin
+
+	// Number of rings per world unit.
+	Scale float64
+
+	// Intensity of the noise applied to the rings.
+	Noise float64
+
+	// Color of the light wood and of the dark rings.
+	ColorA *vmath.Vector3
+	ColorB *vmath.Vector3
+}
+
+func NewWoodTexture(perlin *Perlin, scale float64, noise float64, colorA *vmath.Vector3, colorB *vmath.Vector3) *WoodTexture {
+	var t = new(WoodTexture)
+	t.Perlin = perlin
+	t.Scale = scale
+	t.Noise = noise
+	t.ColorA = colorA
+	t.ColorB = colorB
+	return t
+}
+
+func (t *WoodTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var radius = math.Sqrt(p.X * p.X + p.Z * p.Z) * t.Scale + t.Noise * t.Perlin.Noise(p)
+	var ring = radius - math.Floor(radius)
+
+	// Sharper transition at the end of each ring
+	return Lerp(t.ColorA, t.ColorB, math.Pow(ring, 3.0))
+}
+
+// Linear interpolation between two colors.
+func Lerp(a *vmath.Vector3, b *vmath.Vector3, t float64) *vmath.Vector3 {
+	var color = a.Clone()
+	color.MulScalar(1.0 - t)
+
+	var other = b.Clone()
+	other.MulScalar(t)
+
+	color.Add(other)
+	return color
+}
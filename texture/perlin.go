@@ -0,0 +1,109 @@
+package texture
+
+import (
+	"gotracer/vmath"
+	"math"
+	"math/rand"
+)
+
+// Number of random gradients used by the perlin noise.
+const PerlinPointCount = 256
+
+// Perlin noise generator, produces smooth random values for points in space.
+// Random unit gradients are placed in a lattice and interpolated between the lattice points.
+type Perlin struct {
+	// Random gradient vectors.
+	Gradients [PerlinPointCount]vmath.Vector3
+
+	// Permutations of the gradients for each axis.
+	PermX [PerlinPointCount]int
+	PermY [PerlinPointCount]int
+	PermZ [PerlinPointCount]int
+}
+
+// Create new perlin noise generator, the same seed always produces the same noise.
+func NewPerlin(seed int64) *Perlin {
+	var p = new(Perlin)
+	var random = rand.New(rand.NewSource(seed))
+
+	for i := 0; i < PerlinPointCount; i++ {
+		for {
+			p.Gradients[i].Set(random.Float64() * 2.0 - 1.0, random.Float64() * 2.0 - 1.0, random.Float64() * 2.0 - 1.0)
+			var length = p.Gradients[i].Length()
+			if length > 1e-3 && length <= 1.0 {
+				p.Gradients[i].DivideScalar(length)
+				break
+			}
+		}
+	}
+
+	perlinPermutation(random, &p.PermX)
+	perlinPermutation(random, &p.PermY)
+	perlinPermutation(random, &p.PermZ)
+
+	return p
+}
+
+// Fill a array with a random permutation of its indices.
+func perlinPermutation(random *rand.Rand, perm *[PerlinPointCount]int) {
+	var order = random.Perm(PerlinPointCount)
+	for i := 0; i < PerlinPointCount; i++ {
+		perm[i] = order[i]
+	}
+}
+
+// Noise value at a point, in the [-1, 1] range.
+func (p *Perlin) Noise(point *vmath.Vector3) float64 {
+	var fx = math.Floor(point.X)
+	var fy = math.Floor(point.Y)
+	var fz = math.Floor(point.Z)
+
+	var u = point.X - fx
+	var v = point.Y - fy
+	var w = point.Z - fz
+
+	var i = int(fx)
+	var j = int(fy)
+	var k = int(fz)
+
+	// Hermite smoothing of the interpolation weights
+	var uu = u * u * (3.0 - 2.0 * u)
+	var vv = v * v * (3.0 - 2.0 * v)
+	var ww = w * w * (3.0 - 2.0 * w)
+
+	var accumulated = 0.0
+
+	for di := 0; di < 2; di++ {
+		for dj := 0; dj < 2; dj++ {
+			for dk := 0; dk < 2; dk++ {
+				var index = p.PermX[(i + di) & (PerlinPointCount - 1)] ^ p.PermY[(j + dj) & (PerlinPointCount - 1)] ^ p.PermZ[(k + dk) & (PerlinPointCount - 1)]
+				var gradient = &p.Gradients[index]
+				var weight = vmath.NewVector3(u - float64(di), v - float64(dj), w - float64(dk))
+
+				var a = float64(di) * uu + float64(1 - di) * (1.0 - uu)
+				var b = float64(dj) * vv + float64(1 - dj) * (1.0 - vv)
+				var c = float64(dk) * ww + float64(1 - dk) * (1.0 - ww)
+
+				accumulated += a * b * c * vmath.Dot(gradient, weight)
+			}
+		}
+	}
+
+	return accumulated
+}
+
+// Turbulence is the sum of multiple noise octaves, each with double the frequency and half the weight.
+// The result is always positive.
+func (p *Perlin) Turbulence(point *vmath.Vector3, depth int) float64 {
+	var accumulated = 0.0
+	var weight = 1.0
+	var temp = point.Clone()
+
+	for i := 0; i < depth; i++ {
+		accumulated += weight * p.Noise(temp)
+		weight *= 0.5
+		temp.MulScalar(2.0)
+	}
+
+	return math.Abs(accumulated)
+}
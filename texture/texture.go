@@ -0,0 +1,27 @@
+package texture
+
+import (
+	"gotracer/vmath"
+)
+
+// Texture provides a color for each point of a surface.
+// Textures can be used instead of solid colors in the materials.
+type Texture interface {
+	// Get the color of the texture from the surface UV coordinates and the hit point.
+	Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3
+}
+
+// Solid texture has the same color everywhere.
+type SolidTexture struct {
+	Color *vmath.Vector3
+}
+
+func NewSolidTexture(color *vmath.Vector3) *SolidTexture {
+	var t = new(SolidTexture)
+	t.Color = color
+	return t
+}
+
+func (t *SolidTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	return t.Color.Clone()
+}
@@ -0,0 +1,74 @@
+package texture
+
+import (
+	"gotracer/vmath"
+	"math"
+)
+
+// Worley (cellular) texture, colored by the distance to the closest feature point.
+// One random feature point is placed in each cell of a 3D grid.
+type WorleyTexture struct {
+	// Number of cells per world unit.
+	Scale float64
+
+	// Seed used to place the feature points.
+	Seed int64
+
+	// Color near the feature points and far from them.
+	ColorA *vmath.Vector3
+	ColorB *vmath.Vector3
+}
+
+func NewWorleyTexture(scale float64, seed int64, colorA *vmath.Vector3, colorB *vmath.Vector3) *WorleyTexture {
+	var t = new(WorleyTexture)
+	t.Scale = scale
+	t.Seed = seed
+	t.ColorA = colorA
+	t.ColorB = colorB
+	return t
+}
+
+func (t *WorleyTexture) Value(u float64, v float64, p *vmath.Vector3) *vmath.Vector3 {
+	var x = p.X * t.Scale
+	var y = p.Y * t.Scale
+	var z = p.Z * t.Scale
+
+	var cx = int64(math.Floor(x))
+	var cy = int64(math.Floor(y))
+	var cz = int64(math.Floor(z))
+
+	var closest = math.MaxFloat64
+
+	// The closest feature point is always in the cell of the point or in its neighbors
+	for i := cx - 1; i <= cx + 1; i++ {
+		for j := cy - 1; j <= cy + 1; j++ {
+			for k := cz - 1; k <= cz + 1; k++ {
+				var h = t.hash(i, j, k)
+				var dx = float64(i) + unitHash(h) - x
+				var dy = float64(j) + unitHash(h * 31 + 17) - y
+				var dz = float64(k) + unitHash(h * 53 + 29) - z
+
+				closest = math.Min(closest, dx * dx + dy * dy + dz * dz)
+			}
+		}
+	}
+
+	return Lerp(t.ColorA, t.ColorB, math.Min(math.Sqrt(closest), 1.0))
+}
+
+// Hash the coordinates of a cell with the seed of the texture.
+func (t *WorleyTexture) hash(x int64, y int64, z int64) uint64 {
+	var h = uint64(t.Seed) ^ uint64(x) * 0x9E3779B97F4A7C15 ^ uint64(y) * 0xC2B2AE3D27D4EB4F ^ uint64(z) * 0x165667B19E3779F9
+	h ^= h >> 33
+	h *= 0xFF51AFD7ED558CCD
+	h ^= h >> 33
+	return h
+}
+
+// Map a hash to a value in the [0, 1[ range.
+func unitHash(h uint64) float64 {
+	h ^= h >> 29
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 32
+	return float64(h >> 11) / float64(uint64(1) << 53)
+}
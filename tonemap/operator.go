@@ -0,0 +1,130 @@
+package tonemap
+
+import (
+	"errors"
+	"gotracer/vmath"
+	"math"
+	"strconv"
+)
+
+// Operator compresses high dynamic range linear colors into the [0, 1] range.
+// The result is still linear, the display transfer function is applied after the operator.
+type Operator interface {
+	// Map the color in place.
+	Map(color *vmath.Vector3)
+}
+
+// Clamp operator cuts all values above 1.0.
+type ClampOperator struct {}
+
+func NewClampOperator() *ClampOperator {
+	return new(ClampOperator)
+}
+
+func (o *ClampOperator) Map(color *vmath.Vector3) {
+	color.Set(math.Min(color.X, 1.0), math.Min(color.Y, 1.0), math.Min(color.Z, 1.0))
+}
+
+// Reinhard operator applied to the luminance of the color, L / (1 + L).
+type ReinhardOperator struct {}
+
+func NewReinhardOperator() *ReinhardOperator {
+	return new(ReinhardOperator)
+}
+
+func (o *ReinhardOperator) Map(color *vmath.Vector3) {
+	var luminance = Luminance(color)
+	if luminance > 0 {
+		color.MulScalar(1.0 / (1.0 + luminance))
+	}
+}
+
+// Extended Reinhard operator, luminance values equal or above White are mapped to 1.0.
+type ReinhardExtendedOperator struct {
+	// Smallest luminance value mapped to pure white.
+	White float64
+}
+
+func NewReinhardExtendedOperator(white float64) *ReinhardExtendedOperator {
+	var o = new(ReinhardExtendedOperator)
+	o.White = white
+	return o
+}
+
+func (o *ReinhardExtendedOperator) Map(color *vmath.Vector3) {
+	var luminance = Luminance(color)
+	if luminance > 0 {
+		var mapped = luminance * (1.0 + luminance / (o.White * o.White)) / (1.0 + luminance)
+		color.MulScalar(mapped / luminance)
+	}
+}
+
+// ACES filmic curve, using the fit of the ACES reference rendering transform by Krzysztof Narkowicz.
+type ACESOperator struct {}
+
+func NewACESOperator() *ACESOperator {
+	return new(ACESOperator)
+}
+
+func (o *ACESOperator) Map(color *vmath.Vector3) {
+	color.Set(aces(color.X), aces(color.Y), aces(color.Z))
+}
+
+func aces(x float64) float64 {
+	return (x * (2.51 * x + 0.03)) / (x * (2.43 * x + 0.59) + 0.14)
+}
+
+// Filmic curve used in Uncharted 2 by John Hable.
+type Uncharted2Operator struct {
+	// Linear white point value mapped to 1.0.
+	White float64
+
+	// Exposure multiplier applied before the curve.
+	ExposureBias float64
+}
+
+func NewUncharted2Operator() *Uncharted2Operator {
+	var o = new(Uncharted2Operator)
+	o.White = 11.2
+	o.ExposureBias = 2.0
+	return o
+}
+
+func (o *Uncharted2Operator) Map(color *vmath.Vector3) {
+	var white = uncharted2(o.White)
+	color.Set(uncharted2(color.X * o.ExposureBias) / white, uncharted2(color.Y * o.ExposureBias) / white, uncharted2(color.Z * o.ExposureBias) / white)
+}
+
+func uncharted2(x float64) float64 {
+	const a = 0.15 // Shoulder strength
+	const b = 0.50 // Linear strength
+	const c = 0.10 // Linear angle
+	const d = 0.20 // Toe strength
+	const e = 0.02 // Toe numerator
+	const f = 0.30 // Toe denominator
+	return ((x * (a * x + c * b) + d * e) / (x * (a * x + b) + d * f)) - e / f
+}
+
+// Relative luminance of a linear color (Rec. 709 primaries).
+func Luminance(color *vmath.Vector3) float64 {
+	return vmath.Luminance(color)
+}
+
+// Get a operator with its default parameters by its name.
+// The names available are clamp, reinhard, reinhard-extended, aces and uncharted2.
+func OperatorByName(name string) (Operator, error) {
+	switch name {
+	case "clamp":
+		return NewClampOperator(), nil
+	case "reinhard":
+		return NewReinhardOperator(), nil
+	case "reinhard-extended":
+		return NewReinhardExtendedOperator(4.0), nil
+	case "aces":
+		return NewACESOperator(), nil
+	case "uncharted2":
+		return NewUncharted2Operator(), nil
+	}
+
+	return nil, errors.New("tonemap: unknown operator " + strconv.Quote(name))
+}
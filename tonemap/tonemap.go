@@ -0,0 +1,123 @@
+package tonemap
+
+import (
+	"gotracer/film"
+	"gotracer/vmath"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Tone mapper converts the linear radiance stored in the film into display colors.
+// Exposure and white balance are applied first, then the tone mapping operator and finally the sRGB transfer function.
+type ToneMapper struct {
+	// Operator used to compress the dynamic range.
+	Operator Operator
+
+	// Exposure adjustment in stops (EV), each stop doubles the brightness.
+	Exposure float64
+
+	// Gain multiplied to each color channel to adjust the white balance.
+	WhiteBalance *vmath.Vector3
+}
+
+// Create new tone mapper with a operator, no exposure adjustment and neutral white balance.
+func NewToneMapper(operator Operator) *ToneMapper {
+	var t = new(ToneMapper)
+	t.Operator = operator
+	t.Exposure = 0.0
+	t.WhiteBalance = vmath.NewVector3(1.0, 1.0, 1.0)
+	return t
+}
+
+// Map a linear radiance color to a display color in the [0, 1] range encoded with the sRGB transfer function.
+func (t *ToneMapper) Map(radiance *vmath.Vector3) *vmath.Vector3 {
+	var c = radiance.Clone()
+	c.MulScalar(math.Pow(2.0, t.Exposure))
+	c.Mul(t.WhiteBalance)
+	c.Set(math.Max(c.X, 0.0), math.Max(c.Y, 0.0), math.Max(c.Z, 0.0))
+
+	t.Operator.Map(c)
+
+	c.Set(SRGB(c.X), SRGB(c.Y), SRGB(c.Z))
+	return c
+}
+
+// Get the display color of a film pixel.
+func (t *ToneMapper) Pixel(f *film.Film, x int, y int) *vmath.Vector3 {
+	return t.Map(f.Color(x, y))
+}
+
+// Convert the film into a 16 bit image with the display colors.
+// Image rows start from the top of the image.
+func (t *ToneMapper) Image(f *film.Film) *image.RGBA64 {
+	var img = image.NewRGBA64(image.Rect(0, 0, f.Width, f.Height))
+
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			var c = t.Pixel(f, x, y)
+			img.SetRGBA64(x, f.Height - 1 - y, color.RGBA64{R: uint16(c.X * 0xffff), G: uint16(c.Y * 0xffff), B: uint16(c.Z * 0xffff), A: 0xffff})
+		}
+	}
+
+	return img
+}
+
+// Apply the sRGB transfer function to a linear value, the result is clamped to the [0, 1] range.
+func SRGB(v float64) float64 {
+	if !(v > 0.0) {
+		return 0.0
+	} else if v >= 1.0 {
+		return 1.0
+	} else if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055 * math.Pow(v, 1.0 / 2.4) - 0.055
+}
+
+// Calculate the white balance gains to render a scene lit by a light with a color temperature (in Kelvin) as neutral.
+// A temperature of 6500K produces neutral gains, lower temperatures make the image cooler and higher temperatures warmer.
+func WhiteBalanceTemperature(kelvin float64) *vmath.Vector3 {
+	var reference = blackbody(6500.0)
+	var light = blackbody(kelvin)
+
+	var gains = vmath.NewVector3(light.Y / light.X, 1.0, light.Y / light.Z)
+	gains.X *= reference.X / reference.Y
+	gains.Z *= reference.Z / reference.Y
+
+	return gains
+}
+
+// Approximate linear RGB color of a black body radiator, based on the fit by Tanner Helland.
+func blackbody(kelvin float64) *vmath.Vector3 {
+	var t = math.Max(1000.0, math.Min(kelvin, 40000.0)) / 100.0
+	var r, g, b float64
+
+	if t <= 66.0 {
+		r = 255.0
+		g = 99.4708025861 * math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t - 60.0, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t - 60.0, -0.0755148492)
+	}
+
+	if t >= 66.0 {
+		b = 255.0
+	} else if t <= 19.0 {
+		b = 0.0
+	} else {
+		b = 138.5177312231 * math.Log(t - 10.0) - 305.0447927307
+	}
+
+	// Convert from sRGB encoded values to linear, avoiding zero channels
+	return vmath.NewVector3(linear(r / 255.0), linear(g / 255.0), linear(math.Max(b, 1.0) / 255.0))
+}
+
+// Inverse of the sRGB transfer function.
+func linear(v float64) float64 {
+	v = math.Max(0.0, math.Min(v, 1.0))
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v + 0.055) / 1.055, 2.4)
+}
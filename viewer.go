@@ -0,0 +1,187 @@
+//go:build !headless
+// +build !headless
+
+package main
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"golang.org/x/image/colornames"
+	"gotracer/camera"
+	"gotracer/film"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Open the viewer window, the image is rendered progressively and the camera is controlled with the keyboard and mouse.
+// The viewer is not available in builds with the headless tag, that do not depend on pixelgl.
+func RunViewer() {
+	pixelgl.Run(run)
+}
+
+func run() {
+	var bounds = pixel.R(0, 0, Width, Height)
+	var windowBounds = pixel.R(0, 0, Width * Upscale, Height * Upscale)
+
+	var scene, cam = LoadScene(bounds)
+	var view = cam.GetView()
+	var frame = film.NewFilm(int(Width), int(Height))
+	var picture = pixel.MakePictureData(bounds)
+
+	CreateThreadCopies(scene, cam)
+
+	var config = pixelgl.WindowConfig{
+		Resizable: false,
+		Undecorated: false,
+		VSync: false,
+		Title: "Gotracer",
+		Bounds: windowBounds}
+
+	var window, err = pixelgl.NewWindow(config)
+
+	CheckError(err)
+
+	var delta time.Duration
+
+	for !window.Closed() {
+		
+		var start = time.Now()
+
+		window.Clear(colornames.Black)
+
+		// Without temporal filter each frame is rendered from scratch
+		if !TemporalFilter {
+			frame.Clear()
+		}
+
+		Render(frame, scene, cam)
+		FilmToPicture(frame, picture)
+
+		var sprite = pixel.NewSprite(picture, picture.Bounds())
+		sprite.Draw(window, pixel.IM.Moved(window.Bounds().Center()).Scaled(window.Bounds().Center(), Upscale))
+
+		delta = time.Since(start)
+		log.Printf("Frame time %s, %d samples per pixel", delta, frame.Samples)
+		window.SetTitle("Gotracer (" + strconv.Itoa(frame.Samples) + " spp)")
+
+		var speed = 1.0 * delta.Seconds()
+
+		//Keyboard input
+		if window.Pressed(pixelgl.KeyRight) {
+			view.Position.X += speed
+			UpdateCamera(cam, frame)
+		}
+		if window.Pressed(pixelgl.KeyLeft) {
+			view.Position.X -= speed
+			UpdateCamera(cam, frame)
+		}
+		if window.Pressed(pixelgl.KeyUp) {
+			view.Position.Z -= speed
+			UpdateCamera(cam, frame)
+		}
+		if window.Pressed(pixelgl.KeyDown) {
+			view.Position.Z += speed
+			UpdateCamera(cam, frame)
+		}
+		if window.Pressed(pixelgl.KeyLeftControl) || window.Pressed(pixelgl.KeyRightControl) {
+			view.Position.Y -= speed
+			UpdateCamera(cam, frame)
+		}
+		if window.Pressed(pixelgl.KeySpace) {
+			view.Position.Y += speed
+			UpdateCamera(cam, frame)
+		}
+
+		// Aperture is only available for cameras with defocus blur
+		if defocus := DefocusCamera(cam); defocus != nil {
+			if window.Pressed(pixelgl.KeyW) {
+				defocus.Aperture += 0.1
+				UpdateCamera(cam, frame)
+			}
+			if window.Pressed(pixelgl.KeyS) {
+				defocus.Aperture -= 0.1
+				UpdateCamera(cam, frame)
+			}
+			if window.Pressed(pixelgl.KeyE) {
+				defocus.FocusDistance *= 1.0 + speed
+				UpdateCamera(cam, frame)
+			}
+			if window.Pressed(pixelgl.KeyD) {
+				defocus.FocusDistance /= 1.0 + speed
+				UpdateCamera(cam, frame)
+			}
+		}
+
+		// Field of view is changed in degrees per second
+		if fov, max := CameraFov(cam); fov != nil {
+			if window.Pressed(pixelgl.KeyQ) {
+				*fov = math.Min(*fov + 20.0 * speed, max)
+				UpdateCamera(cam, frame)
+			}
+			if window.Pressed(pixelgl.KeyA) {
+				*fov = math.Max(*fov - 20.0 * speed, 1.0)
+				UpdateCamera(cam, frame)
+			}
+		}
+
+		// Click to focus at the object under the mouse
+		if window.JustPressed(pixelgl.MouseButtonLeft) {
+			var mouse = window.MousePosition()
+			if camera.Focus(cam, scene, mouse.X / windowBounds.W(), mouse.Y / windowBounds.H()) {
+				UpdateCamera(cam, frame)
+			}
+		}
+
+		window.Update()
+	}
+}
+
+// Get the defocus camera used to render, for stereo rigs the center camera is used.
+// Returns nil if the camera has no defocus blur.
+func DefocusCamera(cam camera.Camera) *camera.CameraDefocus {
+	if stereo, ok := cam.(*camera.StereoCamera); ok {
+		cam = stereo.Camera
+	}
+
+	var defocus, _ = cam.(*camera.CameraDefocus)
+	return defocus
+}
+
+// Get the field of view of the camera in degrees and its maximum value, for stereo rigs the center camera is used.
+// Returns nil if the camera has no field of view.
+func CameraFov(cam camera.Camera) (*float64, float64) {
+	if stereo, ok := cam.(*camera.StereoCamera); ok {
+		cam = stereo.Camera
+	}
+
+	switch c := cam.(type) {
+	case *camera.PerspectiveCamera:
+		return &c.Fov, 179.0
+	case *camera.CameraDefocus:
+		return &c.Fov, 179.0
+	case *camera.FisheyeCamera:
+		return &c.Fov, 360.0
+	}
+
+	return nil, 0.0
+}
+
+// Convert the film into a picture to be displayed.
+// The radiance is tone mapped into the [0, 1] range to avoid overflowing the 8 bit color channels.
+//go:norace
+func FilmToPicture(frame *film.Film, picture *pixel.PictureData) {
+	for j := 0; j < frame.Height; j++ {
+		for i := 0; i < frame.Width; i++ {
+			var color = ToneMapper.Pixel(frame, i, j)
+			color.MulScalar(255)
+
+			var index = picture.Index(pixel.Vec{X:float64(i), Y:float64(j)})
+			picture.Pix[index].R = uint8(color.X)
+			picture.Pix[index].G = uint8(color.Y)
+			picture.Pix[index].B = uint8(color.Z)
+			picture.Pix[index].A = 255
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build headless
+// +build headless
+
+package main
+
+import (
+	"log"
+)
+
+// Builds with the headless tag do not depend on pixelgl, the scene is always rendered offline to the output file.
+func RunViewer() {
+	log.Print("Viewer not available in headless builds, rendering to the output file")
+	RunHeadless(*Output, *Samples)
+}
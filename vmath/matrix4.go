@@ -1,6 +1,261 @@
 package vmath
 
+import (
+	"fmt"
+	"math"
+)
+
 // Matrix4 is used to store 4 by 4 matrices, useful to apply transforms
+// Values are stored in row major order, the translation is stored in the last column.
 type Matrix4 struct {
 	Values [16]float64
-}
\ No newline at end of file
+}
+
+// Create new identity matrix.
+func NewMatrix4() *Matrix4 {
+	var m = new(Matrix4)
+	m.Identity()
+	return m
+}
+
+// Create new translation matrix.
+func NewTranslationMatrix4(x float64, y float64, z float64) *Matrix4 {
+	var m = NewMatrix4()
+	m.Values[3] = x
+	m.Values[7] = y
+	m.Values[11] = z
+	return m
+}
+
+// Create new scale matrix.
+func NewScaleMatrix4(x float64, y float64, z float64) *Matrix4 {
+	var m = NewMatrix4()
+	m.Values[0] = x
+	m.Values[5] = y
+	m.Values[10] = z
+	return m
+}
+
+// Create new rotation matrix around the X axis, angle in radians.
+func NewRotationXMatrix4(angle float64) *Matrix4 {
+	var c = math.Cos(angle)
+	var s = math.Sin(angle)
+
+	var m = NewMatrix4()
+	m.Values[5] = c
+	m.Values[6] = -s
+	m.Values[9] = s
+	m.Values[10] = c
+	return m
+}
+
+// Create new rotation matrix around the Y axis, angle in radians.
+func NewRotationYMatrix4(angle float64) *Matrix4 {
+	var c = math.Cos(angle)
+	var s = math.Sin(angle)
+
+	var m = NewMatrix4()
+	m.Values[0] = c
+	m.Values[2] = s
+	m.Values[8] = -s
+	m.Values[10] = c
+	return m
+}
+
+// Create new rotation matrix around the Z axis, angle in radians.
+func NewRotationZMatrix4(angle float64) *Matrix4 {
+	var c = math.Cos(angle)
+	var s = math.Sin(angle)
+
+	var m = NewMatrix4()
+	m.Values[0] = c
+	m.Values[1] = -s
+	m.Values[4] = s
+	m.Values[5] = c
+	return m
+}
+
+// Create new rotation matrix around a arbitrary axis, angle in radians.
+func NewRotationMatrix4(axis *Vector3, angle float64) *Matrix4 {
+	var a = axis.UnitVector()
+	var c = math.Cos(angle)
+	var s = math.Sin(angle)
+	var t = 1.0 - c
+
+	var m = NewMatrix4()
+	m.Values[0] = t * a.X * a.X + c
+	m.Values[1] = t * a.X * a.Y - s * a.Z
+	m.Values[2] = t * a.X * a.Z + s * a.Y
+	m.Values[4] = t * a.X * a.Y + s * a.Z
+	m.Values[5] = t * a.Y * a.Y + c
+	m.Values[6] = t * a.Y * a.Z - s * a.X
+	m.Values[8] = t * a.X * a.Z - s * a.Y
+	m.Values[9] = t * a.Y * a.Z + s * a.X
+	m.Values[10] = t * a.Z * a.Z + c
+	return m
+}
+
+// Set this matrix to the identity matrix.
+func (m *Matrix4) Identity() {
+	m.Values = [16]float64{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Check if the matrix is the identity matrix.
+func (m *Matrix4) IsIdentity() bool {
+	return m.Values == NewMatrix4().Values
+}
+
+// Get the value at a row and column.
+func (m *Matrix4) Get(row int, column int) float64 {
+	return m.Values[row * 4 + column]
+}
+
+// Multiply this matrix by another matrix (m = m * b).
+// The transform of b is applied before the transform of this matrix.
+func (m *Matrix4) Multiply(b *Matrix4) {
+	m.Values = MultiplyMatrix4(m, b).Values
+}
+
+// Multiply two matrices and return the result in a new matrix (a * b).
+func MultiplyMatrix4(a *Matrix4, b *Matrix4) *Matrix4 {
+	var r = new(Matrix4)
+
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			var sum = 0.0
+			for k := 0; k < 4; k++ {
+				sum += a.Values[i * 4 + k] * b.Values[k * 4 + j]
+			}
+			r.Values[i * 4 + j] = sum
+		}
+	}
+
+	return r
+}
+
+// Transpose this matrix.
+func (m *Matrix4) Transpose() {
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			m.Values[i * 4 + j], m.Values[j * 4 + i] = m.Values[j * 4 + i], m.Values[i * 4 + j]
+		}
+	}
+}
+
+// Determinant of the matrix.
+func (m *Matrix4) Determinant() float64 {
+	var v = &m.Values
+	var det = 0.0
+
+	for c := 0; c < 4; c++ {
+		var sign = 1.0
+		if c % 2 == 1 {
+			sign = -1.0
+		}
+		det += sign * v[c] * m.minor(0, c)
+	}
+
+	return det
+}
+
+// Determinant of the 3 by 3 matrix without a row and a column.
+func (m *Matrix4) minor(row int, column int) float64 {
+	var values [9]float64
+	var k = 0
+
+	for i := 0; i < 4; i++ {
+		if i == row {
+			continue
+		}
+		for j := 0; j < 4; j++ {
+			if j == column {
+				continue
+			}
+			values[k] = m.Values[i * 4 + j]
+			k++
+		}
+	}
+
+	return values[0] * (values[4] * values[8] - values[5] * values[7]) -
+		values[1] * (values[3] * values[8] - values[5] * values[6]) +
+		values[2] * (values[3] * values[7] - values[4] * values[6])
+}
+
+// Calculate the inverse of the matrix.
+// Returns false if the matrix cannot be inverted (determinant is zero).
+func (m *Matrix4) Inverse() (*Matrix4, bool) {
+	var det = m.Determinant()
+	if det == 0 {
+		return nil, false
+	}
+
+	var r = new(Matrix4)
+
+	// Inverse is the transposed cofactor matrix divided by the determinant
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			var sign = 1.0
+			if (i + j) % 2 == 1 {
+				sign = -1.0
+			}
+			r.Values[j * 4 + i] = sign * m.minor(i, j) / det
+		}
+	}
+
+	return r, true
+}
+
+// Transform a point, the translation is applied.
+func (m *Matrix4) TransformPoint(p *Vector3) *Vector3 {
+	var v = &m.Values
+	var x = v[0] * p.X + v[1] * p.Y + v[2] * p.Z + v[3]
+	var y = v[4] * p.X + v[5] * p.Y + v[6] * p.Z + v[7]
+	var z = v[8] * p.X + v[9] * p.Y + v[10] * p.Z + v[11]
+	var w = v[12] * p.X + v[13] * p.Y + v[14] * p.Z + v[15]
+
+	if w != 1 && w != 0 {
+		return NewVector3(x / w, y / w, z / w)
+	}
+	return NewVector3(x, y, z)
+}
+
+// Transform a direction vector, the translation is not applied.
+func (m *Matrix4) TransformVector(d *Vector3) *Vector3 {
+	var v = &m.Values
+	return NewVector3(v[0] * d.X + v[1] * d.Y + v[2] * d.Z, v[4] * d.X + v[5] * d.Y + v[6] * d.Z, v[8] * d.X + v[9] * d.Y + v[10] * d.Z)
+}
+
+// Transform a normal vector using the transpose of this matrix, the result is normalized.
+// Normals are transformed by the inverse transpose of the transform, so this method should be called on the inverse matrix.
+func (m *Matrix4) TransformNormal(n *Vector3) *Vector3 {
+	var v = &m.Values
+	var r = NewVector3(v[0] * n.X + v[4] * n.Y + v[8] * n.Z, v[1] * n.X + v[5] * n.Y + v[9] * n.Z, v[2] * n.X + v[6] * n.Y + v[10] * n.Z)
+
+	if r.SquaredLength() > 0 {
+		r.Normalize()
+	}
+	return r
+}
+
+// Clone this matrix into a new matrix.
+func (m *Matrix4) Clone() *Matrix4 {
+	var r = new(Matrix4)
+	r.Values = m.Values
+	return r
+}
+
+// Copy the values of another matrix to this one.
+func (m *Matrix4) Copy(b *Matrix4) {
+	m.Values = b.Values
+}
+
+// Convert to string
+func (m *Matrix4) ToString() string {
+	var v = &m.Values
+	return fmt.Sprintf("[%v, %v, %v, %v; %v, %v, %v, %v; %v, %v, %v, %v; %v, %v, %v, %v]", v[0], v[1], v[2], v[3], v[4], v[5], v[6], v[7], v[8], v[9], v[10], v[11], v[12], v[13], v[14], v[15])
+}
@@ -0,0 +1,46 @@
+package vmath
+
+import (
+	"math"
+)
+
+// Orthonormal basis composed by three perpendicular unit vectors.
+// Used to convert directions from a local space (where W is up) into world space.
+type ONB struct {
+	U *Vector3
+	V *Vector3
+	W *Vector3
+}
+
+// Create new orthonormal basis from a W direction, the other axis are chosen arbitrarily.
+func NewONB(w *Vector3) *ONB {
+	var b = new(ONB)
+	b.W = w.UnitVector()
+
+	var a *Vector3
+	if math.Abs(b.W.X) > 0.9 {
+		a = NewVector3(0.0, 1.0, 0.0)
+	} else {
+		a = NewVector3(1.0, 0.0, 0.0)
+	}
+
+	b.V = Cross(b.W, a).UnitVector()
+	b.U = Cross(b.W, b.V)
+	return b
+}
+
+// Convert a vector from the basis local coordinates into world coordinates.
+func (b *ONB) Local(x float64, y float64, z float64) *Vector3 {
+	var u = b.U.Clone()
+	u.MulScalar(x)
+
+	var v = b.V.Clone()
+	v.MulScalar(y)
+
+	var w = b.W.Clone()
+	w.MulScalar(z)
+
+	u.Add(v)
+	u.Add(w)
+	return u
+}
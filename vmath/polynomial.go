@@ -0,0 +1,142 @@
+package vmath
+
+import (
+	"math"
+)
+
+// Values smaller than this are considered zero by the polynomial solvers.
+const PolynomialEpsilon = 1e-9
+
+// Check if a value is close to zero.
+func isZero(x float64) bool {
+	return x > -PolynomialEpsilon && x < PolynomialEpsilon
+}
+
+// Solve the quadratic equation a*x^2 + b*x + c = 0, returns the real roots.
+func SolveQuadratic(a float64, b float64, c float64) []float64 {
+	if isZero(a) {
+		if isZero(b) {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+
+	var p = b / (2.0 * a)
+	var q = c / a
+	var discriminant = p * p - q
+
+	if isZero(discriminant) {
+		return []float64{-p}
+	}
+	if discriminant < 0 {
+		return nil
+	}
+
+	var sqrtD = math.Sqrt(discriminant)
+	return []float64{sqrtD - p, -sqrtD - p}
+}
+
+// Solve the cubic equation a*x^3 + b*x^2 + c*x + d = 0, returns the real roots.
+// Uses the Cardano method on the normalized equation.
+func SolveCubic(a float64, b float64, c float64, d float64) []float64 {
+	if isZero(a) {
+		return SolveQuadratic(b, c, d)
+	}
+
+	var A = b / a
+	var B = c / a
+	var C = d / a
+
+	// Substitute x = y - A/3 to eliminate the quadratic term (y^3 + 3p*y + 2q = 0)
+	var sqA = A * A
+	var p = (-sqA / 3.0 + B) / 3.0
+	var q = (2.0 / 27.0 * A * sqA - A * B / 3.0 + C) / 2.0
+
+	var cbP = p * p * p
+	var discriminant = q * q + cbP
+
+	var roots []float64
+
+	if isZero(discriminant) {
+		if isZero(q) {
+			roots = []float64{0.0}
+		} else {
+			var u = math.Cbrt(-q)
+			roots = []float64{2.0 * u, -u}
+		}
+	} else if discriminant < 0 {
+		// Three real roots, trigonometric solution
+		var phi = math.Acos(-q / math.Sqrt(-cbP)) / 3.0
+		var t = 2.0 * math.Sqrt(-p)
+		roots = []float64{t * math.Cos(phi), -t * math.Cos(phi + math.Pi / 3.0), -t * math.Cos(phi - math.Pi / 3.0)}
+	} else {
+		var sqrtD = math.Sqrt(discriminant)
+		roots = []float64{math.Cbrt(sqrtD - q) - math.Cbrt(sqrtD + q)}
+	}
+
+	for i := 0; i < len(roots); i++ {
+		roots[i] -= A / 3.0
+	}
+
+	return roots
+}
+
+// Solve the quartic equation a*x^4 + b*x^3 + c*x^2 + d*x + e = 0, returns the real roots.
+// Uses the Ferrari method, the equation is reduced to a cubic resolvent and two quadratic equations.
+func SolveQuartic(a float64, b float64, c float64, d float64, e float64) []float64 {
+	if isZero(a) {
+		return SolveCubic(b, c, d, e)
+	}
+
+	var A = b / a
+	var B = c / a
+	var C = d / a
+	var D = e / a
+
+	// Substitute x = y - A/4 to eliminate the cubic term (y^4 + p*y^2 + q*y + r = 0)
+	var sqA = A * A
+	var p = -3.0 / 8.0 * sqA + B
+	var q = sqA * A / 8.0 - A * B / 2.0 + C
+	var r = -3.0 / 256.0 * sqA * sqA + sqA * B / 16.0 - A * C / 4.0 + D
+
+	var roots []float64
+
+	if isZero(r) {
+		// No constant term, y * (y^3 + p*y + q) = 0
+		roots = append(SolveCubic(1.0, 0.0, p, q), 0.0)
+	} else {
+		// Solve the resolvent cubic and use one of its roots to split into two quadratics
+		var z = SolveCubic(1.0, -p / 2.0, -r, r * p / 2.0 - q * q / 8.0)[0]
+
+		var u = z * z - r
+		var v = 2.0 * z - p
+
+		if isZero(u) {
+			u = 0
+		} else if u > 0 {
+			u = math.Sqrt(u)
+		} else {
+			return nil
+		}
+
+		if isZero(v) {
+			v = 0
+		} else if v > 0 {
+			v = math.Sqrt(v)
+		} else {
+			return nil
+		}
+
+		if q < 0 {
+			v = -v
+		}
+
+		roots = append(SolveQuadratic(1.0, v, z - u), SolveQuadratic(1.0, -v, z + u)...)
+	}
+
+	for i := 0; i < len(roots); i++ {
+		roots[i] -= A / 4.0
+	}
+
+	return roots
+}
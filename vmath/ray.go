@@ -7,6 +7,9 @@ type Ray struct {
 
 	// Normalized direction of the ray
 	Direction *Vector3
+
+	// Time when the ray was casted, used to position moving objects for motion blur.
+	Time float64
 }
 
 // Create new ray from origin point and direction
@@ -17,6 +20,13 @@ func NewRay(origin *Vector3, direction *Vector3) *Ray {
 	return r
 }
 
+// Create new ray casted at a instant of time.
+func NewRayTime(origin *Vector3, direction *Vector3, time float64) *Ray {
+	var r = NewRay(origin, direction)
+	r.Time = time
+	return r
+}
+
 // Create new empty ray
 func NewEmptyRay() *Ray {
 	var r = new(Ray)
@@ -34,7 +44,7 @@ func (r *Ray) PointAtParameter(t float64) *Vector3 {
 }
 
 // Set the values of this array.
-// Internally copies the value of the vectors passed as paramters.
+// Internally copies the value of the vectors passed as paramters, the time is not changed.
 func (r *Ray) Set(origin *Vector3, direction *Vector3) {
 	r.Origin.Copy(origin)
 	r.Direction.Copy(direction)
@@ -45,5 +55,6 @@ func (r *Ray) Clone() *Ray {
 	var nr = new(Ray)
 	nr.Origin = r.Origin.Clone()
 	nr.Direction = r.Direction.Clone()
+	nr.Time = r.Time
 	return nr
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package vmath
+
+// Transform described by its translation, rotation and scale components.
+// Unlike matrices the components can be interpolated without distorting the object, used for animated transforms.
+// The scale is applied first, then the rotation around the X, Y and Z axis and finally the translation.
+type Transform struct {
+	Translation *Vector3
+
+	// Rotation angles around the X, Y and Z axis in radians.
+	Rotation *Vector3
+
+	Scale *Vector3
+}
+
+// Create new identity transform.
+func NewTransform() *Transform {
+	var t = new(Transform)
+	t.Translation = NewVector3(0.0, 0.0, 0.0)
+	t.Rotation = NewVector3(0.0, 0.0, 0.0)
+	t.Scale = NewVector3(1.0, 1.0, 1.0)
+	return t
+}
+
+// Create the transformation matrix of the transform (T * Rz * Ry * Rx * S).
+func (t *Transform) Matrix() *Matrix4 {
+	var m = NewTranslationMatrix4(t.Translation.X, t.Translation.Y, t.Translation.Z)
+	m.Multiply(NewRotationZMatrix4(t.Rotation.Z))
+	m.Multiply(NewRotationYMatrix4(t.Rotation.Y))
+	m.Multiply(NewRotationXMatrix4(t.Rotation.X))
+	m.Multiply(NewScaleMatrix4(t.Scale.X, t.Scale.Y, t.Scale.Z))
+	return m
+}
+
+// Interpolate the components of two transforms.
+func LerpTransform(a *Transform, b *Transform, t float64) *Transform {
+	var r = new(Transform)
+	r.Translation = lerpVector3(a.Translation, b.Translation, t)
+	r.Rotation = lerpVector3(a.Rotation, b.Rotation, t)
+	r.Scale = lerpVector3(a.Scale, b.Scale, t)
+	return r
+}
+
+// Linear interpolation between two vectors.
+func lerpVector3(a *Vector3, b *Vector3, t float64) *Vector3 {
+	return NewVector3(a.X + (b.X - a.X) * t, a.Y + (b.Y - a.Y) * t, a.Z + (b.Z - a.Z) * t)
+}
+
+// Clone this transform into a new transform.
+func (t *Transform) Clone() *Transform {
+	var r = new(Transform)
+	r.Translation = t.Translation.Clone()
+	r.Rotation = t.Rotation.Clone()
+	r.Scale = t.Scale.Clone()
+	return r
+}
@@ -0,0 +1,41 @@
+package vmath
+
+import (
+	"strconv"
+)
+
+// Vector 2 is represented by a x,y values, used for texture coordinates.
+type Vector2 struct {
+	X float64
+	Y float64
+}
+
+// Create new vector2 with values.
+func NewVector2(x float64, y float64) *Vector2 {
+	var v = new(Vector2)
+	v.X = x
+	v.Y = y
+	return v
+}
+
+// Set value of the vector.
+func (v *Vector2) Set(x float64, y float64) {
+	v.X = x
+	v.Y = y
+}
+
+// Return a copy of the vector
+func (v *Vector2) Clone() *Vector2 {
+	return NewVector2(v.X, v.Y)
+}
+
+// Copy the context of another vector to this one
+func (v *Vector2) Copy(b *Vector2) {
+	v.X = b.X
+	v.Y = b.Y
+}
+
+// Generate a string with the vector values
+func (v *Vector2) ToString() string {
+	return "(" + strconv.FormatFloat(v.X, 'f', -1, 64) + ", " + strconv.FormatFloat(v.Y, 'f', -1, 64) + ")"
+}
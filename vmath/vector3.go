@@ -174,6 +174,14 @@ func RandomInUnitSphere() *Vector3 {
 	return p
 }
 
+// Calculate a random unitary vector uniformly distributed in the surface of a sphere.
+func RandomUnitVector() *Vector3 {
+	var z = rand.Float64() * 2.0 - 1.0
+	var a = rand.Float64() * 2.0 * math.Pi
+	var r = math.Sqrt(1.0 - z * z)
+	return NewVector3(r * math.Cos(a), r * math.Sin(a), z)
+}
+
 // Dot product between two vectors
 func Dot(a *Vector3, b *Vector3) float64 {
 	return a.X * b.X + a.Y * b.Y + a.Z * b.Z
@@ -184,6 +192,11 @@ func Cross(a *Vector3, b *Vector3) *Vector3 {
 	return NewVector3(a.Y * b.Z - a.Z * b.Y, -(a.X * b.Z - a.Z * b.X), a.X * b.Y - a.Y * b.X)
 }
 
+// Relative luminance of a linear color (Rec. 709 primaries).
+func Luminance(color *Vector3) float64 {
+	return 0.2126 * color.X + 0.7152 * color.Y + 0.0722 * color.Z
+}
+
 // Return a copy of the vector
 func (v *Vector3) Clone() *Vector3 {
 	return NewVector3(v.X, v.Y, v.Z)
@@ -217,3 +230,28 @@ func (v *Vector3) SquaredLength() float64 {
 func (v *Vector3) ToString() string {
 	return "(" + strconv.FormatFloat(v.X, 'f', -1, 64) + ", " + strconv.FormatFloat(v.Y, 'f', -1, 64) + ", " + strconv.FormatFloat(v.Z, 'f', -1, 64) + ")"
 }
+
+
+// Get a component of the vector by its axis index (0 for X, 1 for Y and 2 for Z).
+func (v *Vector3) Component(axis int) float64 {
+	if axis == 0 {
+		return v.X
+	} else if axis == 1 {
+		return v.Y
+	}
+	return v.Z
+}
+
+// Set each component of the vector to the minimum between this vector and another one.
+func (v *Vector3) Min(b *Vector3) {
+	v.X = math.Min(v.X, b.X)
+	v.Y = math.Min(v.Y, b.Y)
+	v.Z = math.Min(v.Z, b.Z)
+}
+
+// Set each component of the vector to the maximum between this vector and another one.
+func (v *Vector3) Max(b *Vector3) {
+	v.X = math.Max(v.X, b.X)
+	v.Y = math.Max(v.Y, b.Y)
+	v.Z = math.Max(v.Z, b.Z)
+}
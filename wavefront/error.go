@@ -0,0 +1,30 @@
+package wavefront
+
+import (
+	"fmt"
+)
+
+// Error found while reading a OBJ or MTL file, indicates the line where the problem was found.
+type Error struct {
+	// Name of the file.
+	File string
+
+	// Line of the file where the error was found (starting at 1).
+	Line int
+
+	// Description of the error.
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// Create a new error for a line of a file.
+func errorf(file string, line int, format string, args ...interface{}) *Error {
+	var e = new(Error)
+	e.File = file
+	e.Line = line
+	e.Message = fmt.Sprintf(format, args...)
+	return e
+}
@@ -0,0 +1,196 @@
+package wavefront
+
+import (
+	"bufio"
+	"gotracer/material"
+	"gotracer/texture"
+	"gotracer/vmath"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Material declared in a MTL file.
+type Material struct {
+	Name string
+
+	// Diffuse color (Kd).
+	Diffuse *vmath.Vector3
+
+	// Specular color (Ks).
+	Specular *vmath.Vector3
+
+	// Emitted color (Ke).
+	Emission *vmath.Vector3
+
+	// Transmission filter color (Tf).
+	Transmission *vmath.Vector3
+
+	// Specular exponent (Ns), between 0 and 1000.
+	Shininess float64
+
+	// Refractive index (Ni).
+	RefractiveIndex float64
+
+	// Opacity (d), 1 is fully opaque.
+	Dissolve float64
+
+	// Illumination model (illum).
+	Illumination int
+
+	// Path of the diffuse texture image (map_Kd).
+	DiffuseMap string
+
+	// MTL file and line where the diffuse texture was declared, used to report errors when it is loaded.
+	DiffuseMapFile string
+	DiffuseMapLine int
+}
+
+func NewMaterial(name string) *Material {
+	var m = new(Material)
+	m.Name = name
+	m.Diffuse = vmath.NewVector3(0.8, 0.8, 0.8)
+	m.Specular = vmath.NewVector3(0.0, 0.0, 0.0)
+	m.Emission = vmath.NewVector3(0.0, 0.0, 0.0)
+	m.Transmission = vmath.NewVector3(1.0, 1.0, 1.0)
+	m.Shininess = 0.0
+	m.RefractiveIndex = 1.0
+	m.Dissolve = 1.0
+	m.Illumination = 2
+	return m
+}
+
+// Load the materials of a MTL file, by name.
+func LoadMTL(fname string) (map[string]*Material, error) {
+	var file, err = os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var materials = make(map[string]*Material)
+	var current *Material
+	var dir = filepath.Dir(fname)
+
+	var scanner = bufio.NewScanner(file)
+
+	for line := 1; scanner.Scan(); line++ {
+		var fields = strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			if len(fields) < 2 {
+				return nil, errorf(fname, line, "missing material name")
+			}
+			current = NewMaterial(strings.Join(fields[1:], " "))
+			materials[current.Name] = current
+			continue
+		}
+
+		if current == nil {
+			return nil, errorf(fname, line, "%q found before newmtl", fields[0])
+		}
+
+		var err error
+
+		switch fields[0] {
+		case "Kd":
+			current.Diffuse, err = readVector(fields, fname, line)
+		case "Ks":
+			current.Specular, err = readVector(fields, fname, line)
+		case "Ke":
+			current.Emission, err = readVector(fields, fname, line)
+		case "Tf":
+			current.Transmission, err = readVector(fields, fname, line)
+		case "Ns":
+			current.Shininess, err = readFloat(fields, fname, line)
+		case "Ni":
+			current.RefractiveIndex, err = readFloat(fields, fname, line)
+		case "d":
+			current.Dissolve, err = readFloat(fields, fname, line)
+		case "Tr":
+			var transparency float64
+			transparency, err = readFloat(fields, fname, line)
+			current.Dissolve = 1.0 - transparency
+		case "illum":
+			var value float64
+			value, err = readFloat(fields, fname, line)
+			current.Illumination = int(value)
+		case "map_Kd":
+			if len(fields) < 2 {
+				return nil, errorf(fname, line, "missing texture file")
+			}
+			// Texture options are not supported, the file name is the last field
+			current.DiffuseMap = filepath.Join(dir, fields[len(fields) - 1])
+			current.DiffuseMapFile = fname
+			current.DiffuseMapLine = line
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+// Read a single number from the fields of a line, the first field is the keyword.
+func readFloat(fields []string, fname string, line int) (float64, error) {
+	if len(fields) < 2 {
+		return 0, errorf(fname, line, "%q must have a value", fields[0])
+	}
+
+	var value, err = strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, errorf(fname, line, "invalid number %q", fields[1])
+	}
+
+	return value, nil
+}
+
+// Create the renderer material that better represents the MTL material.
+// Emissive materials become lights, transparent materials become dielectrics,
+// reflective materials (illum 3) become metals and all others are lambert.
+// Images are loaded into the cache if not already present.
+func (m *Material) Convert(images map[string]*texture.ImageTexture) (material.Material, error) {
+	if m.Emission.X > 0 || m.Emission.Y > 0 || m.Emission.Z > 0 {
+		return material.NewLightMaterial(m.Emission.Clone()), nil
+	}
+
+	if m.Dissolve < 1.0 || m.Illumination == 4 || m.Illumination == 6 || m.Illumination == 7 {
+		var ior = m.RefractiveIndex
+		if ior <= 1.0 {
+			ior = 1.5
+		}
+		return material.NewDieletricMaterial(ior, m.Transmission.Clone()), nil
+	}
+
+	if m.Illumination == 3 {
+		// Map the specular exponent to the fuzz of the metal, higher exponents are sharper
+		var fuzz = math.Min(math.Sqrt(2.0 / (m.Shininess + 2.0)), 1.0)
+		return material.NewMetalMaterial(m.Specular.Clone(), fuzz), nil
+	}
+
+	if m.DiffuseMap != "" {
+		var image, ok = images[m.DiffuseMap]
+		if !ok {
+			var err error
+			image, err = texture.LoadImageTexture(m.DiffuseMap)
+			if err != nil {
+				return nil, errorf(m.DiffuseMapFile, m.DiffuseMapLine, "cannot load texture %q: %v", m.DiffuseMap, err)
+			}
+			images[m.DiffuseMap] = image
+		}
+		return material.NewLambertMaterialTexture(image), nil
+	}
+
+	return material.NewLambertMaterial(m.Diffuse.Clone()), nil
+}
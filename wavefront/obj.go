@@ -0,0 +1,352 @@
+package wavefront
+
+import (
+	"bufio"
+	"fmt"
+	"gotracer/geometry"
+	"gotracer/material"
+	"gotracer/texture"
+	"gotracer/vmath"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Object loaded from a OBJ file, faces are stored as triangles referencing the vertex data by index.
+type Object struct {
+	// Vertex positions.
+	Vertices []*vmath.Vector3
+
+	// Vertex normals.
+	Normals []*vmath.Vector3
+
+	// Vertex texture coordinates.
+	UVs []*vmath.Vector2
+
+	// Triangles of the object, polygons are triangulated when loaded.
+	Faces []*Face
+
+	// Names of the groups and objects declared in the file, faces reference them by index.
+	Groups []string
+
+	// Materials declared in the MTL libraries of the object, by name.
+	Materials map[string]*Material
+
+	// Texture images loaded by the materials, by path, shared by all meshes created from the object.
+	images map[string]*texture.ImageTexture
+}
+
+// Face (triangle) of a object.
+// Indices start at 0, a index of -1 indicates that the value is not present.
+type Face struct {
+	Vertices [3]int
+	Normals [3]int
+	UVs [3]int
+
+	// Index of the group of the face.
+	Group int
+
+	// Name of the material of the face, empty if no material was set.
+	Material string
+}
+
+// Default material used for faces without material.
+var DefaultMaterial = material.NewLambertMaterial(vmath.NewVector3(0.8, 0.8, 0.8))
+
+// Load a OBJ file and the MTL libraries referenced by it.
+func Load(fname string) (*Object, error) {
+	var file, err = os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file, fname)
+}
+
+// Read a OBJ file from a reader, MTL libraries are loaded relative to the file name.
+func Read(reader io.Reader, fname string) (*Object, error) {
+	var o = new(Object)
+	o.Groups = []string{"default"}
+	o.Materials = make(map[string]*Material)
+	o.images = make(map[string]*texture.ImageTexture)
+
+	var group = 0
+	var usemtl = ""
+	var dir = filepath.Dir(fname)
+
+	var scanner = bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64 * 1024), 16 * 1024 * 1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		var fields = strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			var v, err = readVector(fields, fname, line)
+			if err != nil {
+				return nil, err
+			}
+			o.Vertices = append(o.Vertices, v)
+		case "vn":
+			var v, err = readVector(fields, fname, line)
+			if err != nil {
+				return nil, err
+			}
+			v.Normalize()
+			o.Normals = append(o.Normals, v)
+		case "vt":
+			if len(fields) < 2 {
+				return nil, errorf(fname, line, "texture coordinate must have at least 1 value")
+			}
+			var values, err = readFloats(fields[1:], fname, line)
+			if err != nil {
+				return nil, err
+			}
+			var uv = vmath.NewVector2(values[0], 0.0)
+			if len(values) > 1 {
+				uv.Y = values[1]
+			}
+			o.UVs = append(o.UVs, uv)
+		case "f":
+			if err := o.readFace(fields[1:], group, usemtl, fname, line); err != nil {
+				return nil, err
+			}
+		case "g", "o":
+			var name = strings.Join(fields[1:], " ")
+			group = len(o.Groups)
+			o.Groups = append(o.Groups, name)
+		case "usemtl":
+			if len(fields) < 2 {
+				return nil, errorf(fname, line, "missing material name")
+			}
+			usemtl = strings.Join(fields[1:], " ")
+			if _, ok := o.Materials[usemtl]; !ok {
+				return nil, errorf(fname, line, "unknown material %q", usemtl)
+			}
+		case "mtllib":
+			for _, name := range fields[1:] {
+				var materials, err = LoadMTL(filepath.Join(dir, name))
+				if err != nil {
+					return nil, err
+				}
+				for k, m := range materials {
+					o.Materials[k] = m
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// Read a face from the fields of a "f" line, polygons with more than three vertices are triangulated as a fan.
+func (o *Object) readFace(fields []string, group int, usemtl string, fname string, line int) error {
+	if len(fields) < 3 {
+		return errorf(fname, line, "face must have at least 3 vertices, found %d", len(fields))
+	}
+
+	var vertices = make([]int, len(fields))
+	var uvs = make([]int, len(fields))
+	var normals = make([]int, len(fields))
+
+	for i, field := range fields {
+		var parts = strings.Split(field, "/")
+		if len(parts) > 3 {
+			return errorf(fname, line, "invalid face vertex %q", field)
+		}
+
+		var err error
+		vertices[i], err = readIndex(parts[0], len(o.Vertices), fname, line)
+		if err != nil {
+			return err
+		}
+
+		uvs[i], normals[i] = -1, -1
+		if len(parts) > 1 && parts[1] != "" {
+			uvs[i], err = readIndex(parts[1], len(o.UVs), fname, line)
+			if err != nil {
+				return err
+			}
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			normals[i], err = readIndex(parts[2], len(o.Normals), fname, line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := 1; i < len(fields) - 1; i++ {
+		var f = new(Face)
+		f.Vertices = [3]int{vertices[0], vertices[i], vertices[i + 1]}
+		f.UVs = [3]int{uvs[0], uvs[i], uvs[i + 1]}
+		f.Normals = [3]int{normals[0], normals[i], normals[i + 1]}
+		f.Group = group
+		f.Material = usemtl
+		o.Faces = append(o.Faces, f)
+	}
+
+	return nil
+}
+
+// Create a mesh with all faces of the object.
+// Faces without material use the default material, if it is nil the package DefaultMaterial is used.
+func (o *Object) Mesh(defaultMaterial material.Material) (*geometry.Mesh, error) {
+	return o.mesh(-1, defaultMaterial)
+}
+
+// Create a mesh with the faces of a group (or object) of the object.
+func (o *Object) GroupMesh(name string, defaultMaterial material.Material) (*geometry.Mesh, error) {
+	for i, group := range o.Groups {
+		if group == name {
+			return o.mesh(i, defaultMaterial)
+		}
+	}
+
+	return nil, fmt.Errorf("unknown group %q", name)
+}
+
+// Create a mesh from the faces of the object, if the group is negative all faces are used.
+// The mesh only contains the vertex data referenced by its faces, the indices are remapped to the compacted lists.
+func (o *Object) mesh(group int, defaultMaterial material.Material) (*geometry.Mesh, error) {
+	if defaultMaterial == nil {
+		defaultMaterial = DefaultMaterial
+	}
+
+	var m = new(geometry.Mesh)
+
+	// Index of each material in the mesh material list, the default material is always the first
+	var materials = map[string]int{"": 0}
+	m.Materials = append(m.Materials, defaultMaterial)
+
+	// Index in the mesh of the vertex data used by the faces, by index in the object
+	var vertices = make(map[int]int)
+	var normals = make(map[int]int)
+	var uvs = make(map[int]int)
+
+	for _, f := range o.Faces {
+		if group >= 0 && f.Group != group {
+			continue
+		}
+
+		var index, ok = materials[f.Material]
+		if !ok {
+			var converted, err = o.Materials[f.Material].Convert(o.images)
+			if err != nil {
+				return nil, err
+			}
+			index = len(m.Materials)
+			materials[f.Material] = index
+			m.Materials = append(m.Materials, converted)
+		}
+
+		for k := 0; k < 3; k++ {
+			m.Indices = append(m.Indices, compactIndex(vertices, f.Vertices[k]))
+			m.NormalIndices = append(m.NormalIndices, compactIndex(normals, f.Normals[k]))
+			m.UVIndices = append(m.UVIndices, compactIndex(uvs, f.UVs[k]))
+		}
+		m.FaceMaterials = append(m.FaceMaterials, index)
+	}
+
+	m.Vertices = make([]vmath.Vector3, len(vertices))
+	for i, j := range vertices {
+		m.Vertices[j] = *o.Vertices[i]
+	}
+
+	m.Normals = make([]vmath.Vector3, len(normals))
+	for i, j := range normals {
+		m.Normals[j] = *o.Normals[i]
+	}
+
+	m.UVs = make([]vmath.Vector2, len(uvs))
+	for i, j := range uvs {
+		m.UVs[j] = *o.UVs[i]
+	}
+
+	m.Build()
+
+	return m, nil
+}
+
+// Get the compacted index of a index of the object, indices are assigned in the order that they are first used.
+// Negative indices (value not present) are kept.
+func compactIndex(indices map[int]int, index int) int {
+	if index < 0 {
+		return -1
+	}
+
+	var compact, ok = indices[index]
+	if !ok {
+		compact = len(indices)
+		indices[index] = compact
+	}
+	return compact
+}
+
+// Remove the comment from a line.
+func stripComment(line string) string {
+	var i = strings.IndexByte(line, '#')
+	if i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// Read a vector from the fields of a line, the first field is the keyword.
+func readVector(fields []string, fname string, line int) (*vmath.Vector3, error) {
+	if len(fields) < 4 {
+		return nil, errorf(fname, line, "%q must have 3 values, found %d", fields[0], len(fields) - 1)
+	}
+
+	var values, err = readFloats(fields[1:4], fname, line)
+	if err != nil {
+		return nil, err
+	}
+
+	return vmath.NewVector3(values[0], values[1], values[2]), nil
+}
+
+// Parse a list of numbers.
+func readFloats(fields []string, fname string, line int) ([]float64, error) {
+	var values = make([]float64, len(fields))
+
+	for i, field := range fields {
+		var value, err = strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, errorf(fname, line, "invalid number %q", field)
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// Parse a OBJ index (starting at 1, negative values are relative to the end) into a index starting at 0.
+func readIndex(field string, count int, fname string, line int) (int, error) {
+	var index, err = strconv.Atoi(field)
+	if err != nil {
+		return 0, errorf(fname, line, "invalid index %q", field)
+	}
+
+	if index < 0 {
+		index = count + index
+	} else {
+		index = index - 1
+	}
+
+	if index < 0 || index >= count {
+		return 0, errorf(fname, line, "index %s out of range", field)
+	}
+
+	return index, nil
+}